@@ -0,0 +1,133 @@
+// auth package contains the authentication of outgoing requests.
+package auth
+
+import (
+	"bytes"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/types"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Authenticator authenticates requests. It is invoked before each request is sent, and again with the
+// parsed challenges when the response is 401 Unauthorized or 407 Proxy Authentication Required.
+// Implementations must be safe for concurrent use.
+type Authenticator interface {
+	// Authenticate adds the credentials to the request before it is sent.
+	Authenticate(request *http.Request) error
+	// Challenge handles the challenges of the WWW-Authenticate header of a 401 response, or of the
+	// Proxy-Authenticate header of a 407 response, and reports whether the request should be
+	// authenticated and sent again, e.g. after updating the credentials.
+	Challenge(res *http.Response, challenges []Challenge) (bool, error)
+}
+
+// URLRedactor is implemented by authenticators that add credentials to the request URL,
+// so that the URLs reported in responses and errors do not disclose them.
+type URLRedactor interface {
+	// RedactURL returns a copy of the URL without the credentials.
+	RedactURL(u *url.URL) *url.URL
+}
+
+// Middleware returns the middleware authenticating requests with the authenticator.
+// A request whose challenge is accepted by the authenticator is authenticated and sent
+// once more when its body can be replayed, which is the case for JSON, form and buffered
+// multipart bodies. Prefer network.WithAuthenticator, which does not send the credentials
+// to other hosts when following redirects.
+//
+// Parameters:
+// - authenticator: The authenticator of the requests.
+func Middleware(authenticator Authenticator) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			if err := authenticator.Authenticate(request); err != nil {
+				closeBody(request)
+				return nil, err
+			}
+			res, err := next(request)
+			if err != nil || (res.StatusCode != http.StatusUnauthorized && res.StatusCode != http.StatusProxyAuthRequired) {
+				return res, err
+			}
+			header := constants.WwwAuthenticate
+			if res.StatusCode == http.StatusProxyAuthRequired {
+				header = constants.ProxyAuthenticate
+			}
+			retry, err := authenticator.Challenge(res, ParseChallenges(res.Header.Values(header)))
+			if err != nil {
+				discard(res)
+				return nil, err
+			}
+			if !retry {
+				return res, nil
+			}
+			replayed := replay(request)
+			if replayed == nil {
+				return res, nil
+			}
+			if err := authenticator.Authenticate(replayed); err != nil {
+				// The challenge response is more telling than the failure to authenticate again
+				closeBody(replayed)
+				return res, nil
+			}
+			discard(res)
+			return next(replayed)
+		}
+	}
+}
+
+// replay returns a copy of the request with a fresh body, so it can be sent again.
+// It returns nil when the body was consumed and cannot be replayed.
+func replay(request *http.Request) *http.Request {
+	retry := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil
+		}
+		retry.Body = body
+	} else if request.Body != nil && request.Body != http.NoBody {
+		return nil
+	}
+	return retry
+}
+
+// readBody returns a copy of the request body. Bodies without GetBody are read into memory
+// and replaced, so the request can still be sent.
+func readBody(request *http.Request) ([]byte, error) {
+	if request.GetBody != nil {
+		reader, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close() // Intentionally ignoring error as the copy of the body is read completely
+		}(reader)
+		return io.ReadAll(reader)
+	}
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(request.Body)
+	_ = request.Body.Close() // Intentionally ignoring error as the body is read completely
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
+
+// closeBody closes the body of a request that is not sent.
+func closeBody(request *http.Request) {
+	if request.Body != nil {
+		_ = request.Body.Close() // Intentionally ignoring error as the request is abandoned
+	}
+}
+
+// discard reads and closes the body of a response that is not returned.
+func discard(res *http.Response) {
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close() // Intentionally ignoring error as the response is discarded
+}
@@ -0,0 +1,95 @@
+package auth
+
+import "strings"
+
+// Challenge is an authentication challenge of a WWW-Authenticate or Proxy-Authenticate header.
+type Challenge struct {
+	// Scheme is the authentication scheme, e.g. "Basic", "Bearer" or "Digest".
+	Scheme string
+	// Params are the auth-params of the challenge by lowercase name, e.g. "realm".
+	Params map[string]string
+}
+
+// ParseChallenges parses the authentication challenges of WWW-Authenticate or Proxy-Authenticate
+// header values (RFC 9110 section 11.6.1), e.g. `Digest realm="api", nonce="abc", Basic realm="api"`.
+// A token68 credential, e.g. `Negotiate abc==`, is stored under the empty parameter name.
+//
+// Parameters:
+// - values: The values of the header.
+func ParseChallenges(values []string) []Challenge {
+	var challenges []Challenge
+	for _, s := range values {
+		for {
+			s = strings.TrimLeft(s, " \t,")
+			var scheme string
+			if scheme, s = readToken(s); scheme == "" {
+				break
+			}
+			c := Challenge{Scheme: scheme, Params: map[string]string{}}
+			if rest := strings.TrimLeft(s, " \t"); rest != "" && rest[0] != ',' && !isParam(rest) {
+				end := strings.IndexByte(rest, ',')
+				if end < 0 {
+					end = len(rest)
+				}
+				c.Params[""] = strings.TrimSpace(rest[:end])
+				s = rest[end:]
+			}
+			for {
+				rest := strings.TrimLeft(s, " \t,")
+				if !isParam(rest) {
+					s = rest
+					break
+				}
+				name, after := readToken(rest)
+				var value string
+				value, s = readValue(strings.TrimLeft(strings.TrimLeft(after, " \t")[1:], " \t"))
+				c.Params[strings.ToLower(name)] = value
+			}
+			challenges = append(challenges, c)
+		}
+	}
+	return challenges
+}
+
+// isParam checks if s starts with an auth-param, a token followed by "=" and a value,
+// rather than with a new challenge or a token68 credential ending with "=".
+func isParam(s string) bool {
+	name, after := readToken(s)
+	after = strings.TrimLeft(after, " \t")
+	if name == "" || !strings.HasPrefix(after, "=") {
+		return false
+	}
+	value := strings.TrimLeft(after[1:], " \t")
+	return value != "" && value[0] != '=' && value[0] != ','
+}
+
+// readToken reads a token at the start of s and returns it with the rest of s.
+func readToken(s string) (string, string) {
+	end := strings.IndexAny(s, " \t,=\"")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// readValue reads a token or a quoted string at the start of s and returns it with the rest of s.
+func readValue(s string) (string, string) {
+	if !strings.HasPrefix(s, `"`) {
+		return readToken(s)
+	}
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				value.WriteByte(s[i])
+			}
+		case '"':
+			return value.String(), s[i+1:]
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return value.String(), ""
+}
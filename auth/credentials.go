@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"net/http"
+	"net/url"
+)
+
+// Basic authenticates requests with HTTP Basic authentication (RFC 7617).
+type Basic struct {
+	username string
+	password string
+}
+
+// Bearer authenticates requests with a static bearer token (RFC 6750).
+type Bearer struct {
+	token string
+}
+
+// APIKeyHeader authenticates requests with an API key sent in a header.
+type APIKeyHeader struct {
+	name string
+	key  string
+}
+
+// APIKeyQuery authenticates requests with an API key sent as a query parameter. The key is visible
+// in the request URL, e.g. to middleware and in server and proxy logs; the URLs reported by the client
+// in responses and errors have it redacted. Prefer APIKeyHeader when the server supports it.
+type APIKeyQuery struct {
+	name string
+	key  string
+}
+
+// NewBasic creates an HTTP Basic authenticator.
+//
+// Parameters:
+// - username: The username.
+// - password: The password.
+func NewBasic(username string, password string) *Basic {
+	return &Basic{username: username, password: password}
+}
+
+// Authenticate sets the Authorization header of the request with the credentials.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (b *Basic) Authenticate(request *http.Request) error {
+	request.SetBasicAuth(b.username, b.password)
+	return nil
+}
+
+// Challenge reports that the request is not sent again, as the credentials do not change.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (b *Basic) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
+
+// NewBearer creates an authenticator sending a static bearer token.
+//
+// Parameters:
+// - token: The bearer token.
+func NewBearer(token string) *Bearer {
+	return &Bearer{token: token}
+}
+
+// Authenticate sets the Authorization header of the request with the token.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (b *Bearer) Authenticate(request *http.Request) error {
+	request.Header.Set(constants.Authorization, "Bearer "+b.token)
+	return nil
+}
+
+// Challenge reports that the request is not sent again, as the token does not change.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (b *Bearer) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
+
+// NewAPIKeyHeader creates an authenticator sending an API key in a header, e.g. "X-API-Key".
+//
+// Parameters:
+// - name: The name of the header.
+// - key: The API key.
+func NewAPIKeyHeader(name string, key string) *APIKeyHeader {
+	return &APIKeyHeader{name: name, key: key}
+}
+
+// Authenticate sets the header of the request with the API key.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (a *APIKeyHeader) Authenticate(request *http.Request) error {
+	request.Header.Set(a.name, a.key)
+	return nil
+}
+
+// Challenge reports that the request is not sent again, as the API key does not change.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (a *APIKeyHeader) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
+
+// NewAPIKeyQuery creates an authenticator sending an API key as a query parameter, e.g. "api_key".
+// The key is visible in the request URL.
+//
+// Parameters:
+// - name: The name of the query parameter.
+// - key: The API key.
+func NewAPIKeyQuery(name string, key string) *APIKeyQuery {
+	return &APIKeyQuery{name: name, key: key}
+}
+
+// Authenticate sets the query parameter of the request URL with the API key.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (a *APIKeyQuery) Authenticate(request *http.Request) error {
+	u := *request.URL
+	query := u.Query()
+	query.Set(a.name, a.key)
+	u.RawQuery = query.Encode()
+	request.URL = &u
+	return nil
+}
+
+// RedactURL returns a copy of the URL whose API key query parameter is replaced with "xxxxx".
+//
+// Parameters:
+// - u: The URL to redact.
+func (a *APIKeyQuery) RedactURL(u *url.URL) *url.URL {
+	redacted := *u
+	query := redacted.Query()
+	if query.Has(a.name) {
+		query.Set(a.name, "xxxxx")
+		redacted.RawQuery = query.Encode()
+	}
+	return &redacted
+}
+
+// Challenge reports that the request is not sent again, as the API key does not change.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (a *APIKeyQuery) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/types"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// digestAlgorithms are the supported Digest algorithms, from the strongest to the weakest.
+var digestAlgorithms = []string{"SHA-512-256", "SHA-512-256-SESS", "SHA-256", "SHA-256-SESS", "MD5", "MD5-SESS"}
+
+// DigestSettings configures HTTP Digest authentication.
+type DigestSettings struct {
+	// Username and Password are the credentials of the user.
+	Username string
+	Password string
+}
+
+// Digest authenticates requests with HTTP Digest authentication (RFC 7616). A request answered with
+// a Digest challenge is sent again with the credentials, and later requests to the same host reuse
+// the challenge with an incremented nonce count. It is safe for concurrent use.
+type Digest struct {
+	settings   DigestSettings
+	mu         sync.Mutex
+	challenges map[digestKey]*digestState
+}
+
+// digestKey identifies the origin of a challenge: the host of the request, and whether the challenge
+// came from the proxy used to reach it.
+type digestKey struct {
+	host  string
+	proxy bool
+}
+
+// digestState is the last challenge received from an origin and the nonce count of its answers.
+type digestState struct {
+	challenge *Challenge
+	count     uint32
+}
+
+// NewDigest creates an HTTP Digest authenticator.
+//
+// Parameters:
+// - settings: The Digest settings.
+func NewDigest(settings DigestSettings) *Digest {
+	return &Digest{settings: settings, challenges: map[digestKey]*digestState{}}
+}
+
+// Middleware returns the middleware answering Digest challenges. Requests are sent again after a
+// challenge only when their body can be replayed, which is the case for JSON, form and buffered
+// multipart bodies. Register it with network.WithMiddleware, or register the authenticator itself
+// with network.WithAuthenticator.
+func (d *Digest) Middleware() types.Middleware {
+	return Middleware(d)
+}
+
+// Authenticate sets the Authorization header answering the last challenge received from the host
+// of the request, if any, and the Proxy-Authorization header answering the last challenge received
+// from the proxy used to reach it.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (d *Digest) Authenticate(request *http.Request) error {
+	for _, proxy := range []bool{false, true} {
+		if err := d.authorize(request, digestKey{host: host(request), proxy: proxy}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Challenge keeps the Digest challenge with the strongest supported algorithm for the host of the
+// request, so the request is sent again answering it. A new challenge for the nonce that was just
+// answered means the credentials were rejected, unless the server marks the nonce as stale.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (d *Digest) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	digest := digestChallenge(challenges)
+	if digest == nil {
+		return false, nil
+	}
+	key := digestKey{proxy: res.StatusCode == http.StatusProxyAuthRequired}
+	if res.Request != nil {
+		key.host = host(res.Request)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if state := d.challenges[key]; state != nil && state.challenge.Params["nonce"] == digest.Params["nonce"] &&
+		!strings.EqualFold(digest.Params["stale"], "true") {
+		return false, nil
+	}
+	d.challenges[key] = &digestState{challenge: digest}
+	return true, nil
+}
+
+// authorize sets the authorization header answering the last challenge received from the origin, if any.
+func (d *Digest) authorize(request *http.Request, key digestKey) error {
+	d.mu.Lock()
+	state := d.challenges[key]
+	if state == nil {
+		d.mu.Unlock()
+		return nil
+	}
+	state.count++
+	c, count := state.challenge, state.count
+	d.mu.Unlock()
+
+	algorithm := strings.ToUpper(c.Params["algorithm"])
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	h := digestHash(algorithm)
+	realm, nonce := c.Params["realm"], c.Params["nonce"]
+	uri := request.URL.RequestURI()
+	cnonce := newCnonce()
+	nc := fmt.Sprintf("%08x", count)
+
+	ha1 := h(d.settings.Username + ":" + realm + ":" + d.settings.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+	qop := selectQop(c.Params["qop"])
+	ha2 := h(request.Method + ":" + uri)
+	if qop == "auth-int" {
+		if request.GetBody == nil && request.Body != nil && request.Body != http.NoBody {
+			return fmt.Errorf("digest qop auth-int requires a replayable request body")
+		}
+		body, err := readBody(request)
+		if err != nil {
+			return err
+		}
+		ha2 = h(request.Method + ":" + uri + ":" + h(string(body)))
+	}
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	username := d.settings.Username
+	userhash := strings.EqualFold(c.Params["userhash"], "true")
+	if userhash {
+		username = h(username + ":" + realm)
+	}
+	fields := []string{
+		"username=" + quote(username),
+		"realm=" + quote(realm),
+		"nonce=" + quote(nonce),
+		"uri=" + quote(uri),
+		"algorithm=" + algorithm,
+		"response=" + quote(response),
+	}
+	if qop != "" {
+		fields = append(fields, "qop="+qop, "nc="+nc, "cnonce="+quote(cnonce))
+	}
+	if opaque, ok := c.Params["opaque"]; ok {
+		fields = append(fields, "opaque="+quote(opaque))
+	}
+	if userhash {
+		fields = append(fields, "userhash=true")
+	}
+	header := constants.Authorization
+	if key.proxy {
+		header = constants.ProxyAuthorization
+	}
+	request.Header.Set(header, "Digest "+strings.Join(fields, ", "))
+	return nil
+}
+
+// digestChallenge returns the Digest challenge with the strongest supported algorithm.
+func digestChallenge(challenges []Challenge) *Challenge {
+	var selected *Challenge
+	rank := len(digestAlgorithms)
+	for i := range challenges {
+		if !strings.EqualFold(challenges[i].Scheme, "Digest") {
+			continue
+		}
+		algorithm := strings.ToUpper(challenges[i].Params["algorithm"])
+		if algorithm == "" {
+			algorithm = "MD5"
+		}
+		if index := slices.Index(digestAlgorithms, algorithm); index >= 0 && index < rank {
+			selected, rank = &challenges[i], index
+		}
+	}
+	return selected
+}
+
+// digestHash returns the hex encoded hash function of the Digest algorithm.
+func digestHash(algorithm string) func(string) string {
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "SHA-512-256":
+		newHash = sha512.New512_256
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		newHash = md5.New
+	}
+	return func(data string) string {
+		h := newHash()
+		h.Write([]byte(data))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// selectQop selects the quality of protection among the offered ones, preferring auth, which
+// does not require the body to be read, over auth-int.
+func selectQop(offered string) string {
+	var options []string
+	for _, option := range strings.Split(offered, ",") {
+		options = append(options, strings.TrimSpace(option))
+	}
+	switch {
+	case slices.Contains(options, "auth"):
+		return "auth"
+	case slices.Contains(options, "auth-int"):
+		return "auth-int"
+	}
+	return ""
+}
+
+// newCnonce returns a random client nonce.
+func newCnonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand never fails on supported platforms
+	return hex.EncodeToString(b)
+}
+
+// quote returns the value as a quoted string.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
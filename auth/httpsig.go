@@ -0,0 +1,501 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"github.com/xander1235/gorest/types"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// signatureHeader carries the signatures of a message (RFC 9421 section 4.2).
+	signatureHeader = "Signature"
+	// signatureInputHeader carries the covered components and parameters of the signatures (RFC 9421 section 4.1).
+	signatureInputHeader = "Signature-Input"
+	// contentDigestHeader carries the digest of the message content (RFC 9530).
+	contentDigestHeader = "Content-Digest"
+)
+
+// SignatureKey is a key signing or verifying HTTP message signatures.
+type SignatureKey struct {
+	// ID identifies the key, sent in the keyid parameter.
+	ID string
+	// Algorithm is the signature algorithm of the key.
+	Algorithm enums.SignatureAlgorithm
+	// Key is the key material: a []byte shared secret for HMAC, an ed25519.PrivateKey,
+	// *ecdsa.PrivateKey or *rsa.PrivateKey to sign, and an ed25519.PublicKey,
+	// *ecdsa.PublicKey or *rsa.PublicKey to verify.
+	Key any
+}
+
+// MessageSignatureSettings configures the HTTP message signatures of RFC 9421.
+type MessageSignatureSettings struct {
+	// Key is the key signing the requests. Requests are not signed when it has no key material.
+	Key SignatureKey
+	// Components are the components covered by the request signature, such as "@method",
+	// "@target-uri", "@authority", "@path", "@query", "content-digest" or header names,
+	// which are lowercased as RFC 9421 requires.
+	// Defaults to "@method", "@target-uri" and, for requests with a body, "content-digest".
+	// The Content-Digest header is computed when it is covered and not set.
+	Components []string
+	// Label is the label of the signature in the Signature and Signature-Input headers. Defaults to "sig1".
+	Label string
+	// Expires is how long signatures are valid, sent in the expires parameter when set.
+	Expires time.Duration
+	// Tag is the application-specific tag parameter, sent when set.
+	Tag string
+	// ResponseKeys are the keys verifying the signatures of responses, matched by key ID.
+	// Responses are only verified when keys are set, and must then carry a valid signature.
+	ResponseKeys []SignatureKey
+	// ResponseComponents are the components the signature of a response must cover, e.g. "@status" or "x-request-id".
+	// Defaults to "@status". The signature of a response with a body must cover "content-digest" in any case.
+	ResponseComponents []string
+}
+
+// MessageSigner signs requests and verifies responses with HTTP message signatures (RFC 9421).
+// It is safe for concurrent use.
+type MessageSigner struct {
+	settings MessageSignatureSettings
+}
+
+// signatureInput is a parsed member of the Signature-Input header.
+type signatureInput struct {
+	label      string
+	components []string
+	params     map[string]string
+	// raw is the serialized inner list and parameters, as covered by @signature-params
+	raw string
+}
+
+// NewMessageSigner creates an HTTP message signer.
+//
+// Parameters:
+// - settings: The message signature settings.
+func NewMessageSigner(settings MessageSignatureSettings) *MessageSigner {
+	if settings.Label == "" {
+		settings.Label = "sig1"
+	}
+	return &MessageSigner{settings: settings}
+}
+
+// Middleware returns the middleware signing requests and, when response keys are set, verifying
+// the signatures of responses before they are decoded. Responses that fail verification are
+// reported as an *errors.SignatureError. Register it with network.WithMiddleware.
+func (s *MessageSigner) Middleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			verify := len(s.settings.ResponseKeys) > 0
+			if verify && request.Header.Get(constants.AcceptEncoding) == "" {
+				// The digest covers the content as sent, so responses must not be decoded before verification
+				request.Header.Set(constants.AcceptEncoding, "identity")
+			}
+			if s.settings.Key.Key != nil {
+				if err := s.Sign(request, time.Now()); err != nil {
+					closeBody(request)
+					return nil, err
+				}
+			}
+			res, err := next(request)
+			if err != nil || !verify {
+				return res, err
+			}
+
+			body, err := io.ReadAll(res.Body)
+			_ = res.Body.Close() // Intentionally ignoring error as the body was read completely
+			if err != nil {
+				return nil, err
+			}
+			if err = s.Verify(res, body); err != nil {
+				return nil, err
+			}
+			res.Body = io.NopCloser(bytes.NewReader(body))
+			return res, nil
+		}
+	}
+}
+
+// Sign signs the request in place, setting the Signature and Signature-Input headers.
+//
+// Parameters:
+// - request: The request to sign.
+// - created: The creation time of the signature.
+func (s *MessageSigner) Sign(request *http.Request, created time.Time) error {
+	components := make([]string, len(s.settings.Components))
+	for i, component := range s.settings.Components {
+		// Field names are covered in lowercase, derived components are case-sensitive
+		if !strings.HasPrefix(component, "@") {
+			component = strings.ToLower(component)
+		}
+		components[i] = component
+	}
+	if len(components) == 0 {
+		components = []string{"@method", "@target-uri"}
+		if body, err := readBody(request); err != nil {
+			return err
+		} else if len(body) > 0 {
+			components = append(components, "content-digest")
+		}
+	}
+	for _, component := range components {
+		if component == "content-digest" && request.Header.Get(contentDigestHeader) == "" {
+			body, err := readBody(request)
+			if err != nil {
+				return err
+			}
+			digest := sha256.Sum256(body)
+			request.Header.Set(contentDigestHeader, "sha-256=:"+base64.StdEncoding.EncodeToString(digest[:])+":")
+		}
+	}
+
+	quoted := make([]string, len(components))
+	for i, component := range components {
+		quoted[i] = strconv.Quote(component)
+	}
+	params := "(" + strings.Join(quoted, " ") + ");created=" + strconv.FormatInt(created.Unix(), 10)
+	if s.settings.Expires > 0 {
+		params += ";expires=" + strconv.FormatInt(created.Add(s.settings.Expires).Unix(), 10)
+	}
+	params += ";keyid=" + strconv.Quote(s.settings.Key.ID) + ";alg=" + strconv.Quote(s.settings.Key.Algorithm.String())
+	if s.settings.Tag != "" {
+		params += ";tag=" + strconv.Quote(s.settings.Tag)
+	}
+
+	base, err := signatureBase(components, params, func(component string) (string, error) {
+		return requestComponent(request, component)
+	})
+	if err != nil {
+		return err
+	}
+	signature, err := signMessage(s.settings.Key, base)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(signatureInputHeader, s.settings.Label+"="+params)
+	request.Header.Set(signatureHeader, s.settings.Label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return nil
+}
+
+// Verify verifies the signatures of the response. It succeeds when one of the signatures was made
+// with a response key, covers the required components and has not expired. The required components
+// are the response components, and content-digest when the response has a body, whose digest is then
+// checked against the body.
+//
+// Parameters:
+// - res: The response to verify.
+// - body: The response body.
+func (s *MessageSigner) Verify(res *http.Response, body []byte) error {
+	inputs, err := parseSignatureInputs(strings.Join(res.Header.Values(signatureInputHeader), ", "))
+	if err != nil {
+		return &errors.SignatureError{Reason: err.Error()}
+	}
+	signatures := parseSignatures(strings.Join(res.Header.Values(signatureHeader), ", "))
+	if len(inputs) == 0 || len(signatures) == 0 {
+		return &errors.SignatureError{Reason: "response is not signed"}
+	}
+
+	reason := "no signature made with a response key"
+	for _, input := range inputs {
+		key, ok := s.responseKey(input.params["keyid"], input.params["alg"])
+		signature, signed := signatures[input.label]
+		if !ok || !signed {
+			continue
+		}
+		if err = s.verifyInput(res, body, input, key, signature); err != nil {
+			reason = err.Error()
+			continue
+		}
+		return nil
+	}
+	return &errors.SignatureError{Reason: reason}
+}
+
+// verifyInput verifies a single signature of the response.
+func (s *MessageSigner) verifyInput(res *http.Response, body []byte, input signatureInput, key SignatureKey, signature []byte) error {
+	required := s.settings.ResponseComponents
+	if len(required) == 0 {
+		required = []string{"@status"}
+	}
+	if len(body) > 0 {
+		required = append(slices.Clip(required), "content-digest")
+	}
+	for _, component := range required {
+		if !strings.HasPrefix(component, "@") {
+			component = strings.ToLower(component)
+		}
+		if !slices.Contains(input.components, component) {
+			return fmt.Errorf("signature %s does not cover %s", input.label, component)
+		}
+	}
+	if expires, err := strconv.ParseInt(input.params["expires"], 10, 64); err == nil && time.Now().Unix() > expires {
+		return fmt.Errorf("signature %s expired", input.label)
+	}
+	if slices.Contains(input.components, "content-digest") {
+		if err := verifyContentDigest(res.Header.Get(contentDigestHeader), body); err != nil {
+			return err
+		}
+	}
+	base, err := signatureBase(input.components, input.raw, func(component string) (string, error) {
+		return responseComponent(res, component)
+	})
+	if err != nil {
+		return err
+	}
+	return verifyMessage(key, base, signature)
+}
+
+// responseKey returns the response key with the key ID, or the only response key when the signature has no key ID.
+func (s *MessageSigner) responseKey(keyID string, algorithm string) (SignatureKey, bool) {
+	for _, key := range s.settings.ResponseKeys {
+		if (key.ID == keyID || (keyID == "" && len(s.settings.ResponseKeys) == 1)) && (algorithm == "" || algorithm == key.Algorithm.String()) {
+			return key, true
+		}
+	}
+	return SignatureKey{}, false
+}
+
+// signatureBase creates the signature base of the covered components (RFC 9421 section 2.5).
+func signatureBase(components []string, params string, value func(string) (string, error)) ([]byte, error) {
+	var base strings.Builder
+	for _, component := range components {
+		componentValue, err := value(component)
+		if err != nil {
+			return nil, err
+		}
+		base.WriteString(strconv.Quote(component) + ": " + componentValue + "\n")
+	}
+	base.WriteString(`"@signature-params": ` + params)
+	return []byte(base.String()), nil
+}
+
+// requestComponent returns the value of a component of the request.
+func requestComponent(request *http.Request, component string) (string, error) {
+	switch component {
+	case "@method":
+		return request.Method, nil
+	case "@target-uri":
+		return request.URL.String(), nil
+	case "@authority":
+		return strings.ToLower(host(request)), nil
+	case "@scheme":
+		return strings.ToLower(request.URL.Scheme), nil
+	case "@request-target":
+		return request.URL.RequestURI(), nil
+	case "@path":
+		if p := request.URL.EscapedPath(); p != "" {
+			return p, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + request.URL.RawQuery, nil
+	}
+	return headerComponent(request.Header, component)
+}
+
+// responseComponent returns the value of a component of the response.
+func responseComponent(res *http.Response, component string) (string, error) {
+	if component == "@status" {
+		return strconv.Itoa(res.StatusCode), nil
+	}
+	return headerComponent(res.Header, component)
+}
+
+// headerComponent returns the value of a header component, its values trimmed and joined with commas.
+func headerComponent(header http.Header, component string) (string, error) {
+	if strings.HasPrefix(component, "@") {
+		return "", fmt.Errorf("unsupported signature component %s", component)
+	}
+	values := header.Values(component)
+	if len(values) == 0 {
+		return "", fmt.Errorf("signature component %s is missing", component)
+	}
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.TrimSpace(value)
+	}
+	return strings.Join(trimmed, ", "), nil
+}
+
+// signMessage signs the signature base with the key.
+func signMessage(key SignatureKey, base []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case enums.HmacSha256:
+		if secret, ok := key.Key.([]byte); ok {
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(base)
+			return mac.Sum(nil), nil
+		}
+	case enums.Ed25519:
+		if private, ok := key.Key.(ed25519.PrivateKey); ok {
+			return ed25519.Sign(private, base), nil
+		}
+	case enums.EcdsaP256Sha256:
+		if private, ok := key.Key.(*ecdsa.PrivateKey); ok {
+			digest := sha256.Sum256(base)
+			r, sig, err := ecdsa.Sign(rand.Reader, private, digest[:])
+			if err != nil {
+				return nil, err
+			}
+			// The signature is the concatenation of r and s, 32 bytes each (RFC 9421 section 3.3.4)
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			sig.FillBytes(signature[32:])
+			return signature, nil
+		}
+	case enums.RsaPssSha512:
+		if private, ok := key.Key.(*rsa.PrivateKey); ok {
+			digest := sha512.Sum512(base)
+			return rsa.SignPSS(rand.Reader, private, crypto.SHA512, digest[:], &rsa.PSSOptions{SaltLength: 64})
+		}
+	}
+	return nil, fmt.Errorf("key %s of type %T cannot sign with %s", key.ID, key.Key, key.Algorithm)
+}
+
+// verifyMessage verifies the signature of the signature base with the key.
+func verifyMessage(key SignatureKey, base []byte, signature []byte) error {
+	valid := false
+	switch key.Algorithm {
+	case enums.HmacSha256:
+		if secret, ok := key.Key.([]byte); ok {
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(base)
+			valid = hmac.Equal(mac.Sum(nil), signature)
+		}
+	case enums.Ed25519:
+		if public, ok := key.Key.(ed25519.PublicKey); ok {
+			valid = ed25519.Verify(public, base, signature)
+		}
+	case enums.EcdsaP256Sha256:
+		if public, ok := key.Key.(*ecdsa.PublicKey); ok && len(signature) == 64 {
+			digest := sha256.Sum256(base)
+			valid = ecdsa.Verify(public, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+		}
+	case enums.RsaPssSha512:
+		if public, ok := key.Key.(*rsa.PublicKey); ok {
+			digest := sha512.Sum512(base)
+			valid = rsa.VerifyPSS(public, crypto.SHA512, digest[:], signature, &rsa.PSSOptions{SaltLength: 64}) == nil
+		}
+	}
+	if !valid {
+		return fmt.Errorf("signature does not match key %s", key.ID)
+	}
+	return nil
+}
+
+// verifyContentDigest checks the sha-256 or sha-512 digest of the Content-Digest header against the body.
+func verifyContentDigest(header string, body []byte) error {
+	for _, member := range splitTopLevel(header) {
+		algorithm, value, _ := strings.Cut(strings.TrimSpace(member), "=")
+		expected, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+		if err != nil {
+			continue
+		}
+		var actual []byte
+		switch algorithm {
+		case "sha-256":
+			digest := sha256.Sum256(body)
+			actual = digest[:]
+		case "sha-512":
+			digest := sha512.Sum512(body)
+			actual = digest[:]
+		default:
+			continue
+		}
+		if !hmac.Equal(expected, actual) {
+			return fmt.Errorf("content digest does not match the body")
+		}
+		return nil
+	}
+	return fmt.Errorf("content digest is missing")
+}
+
+// parseSignatureInputs parses the members of a Signature-Input header, a structured field
+// dictionary of inner lists of component names with parameters.
+func parseSignatureInputs(header string) ([]signatureInput, error) {
+	var inputs []signatureInput
+	for _, member := range splitTopLevel(header) {
+		label, value, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok || !strings.HasPrefix(value, "(") {
+			return nil, fmt.Errorf("malformed signature input %q", member)
+		}
+		end := strings.Index(value, ")")
+		if end < 0 {
+			return nil, fmt.Errorf("malformed signature input %q", member)
+		}
+		input := signatureInput{label: label, params: map[string]string{}, raw: value}
+		for _, item := range strings.Fields(value[1:end]) {
+			component, err := strconv.Unquote(item)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported signature component %s", item)
+			}
+			input.components = append(input.components, component)
+		}
+		for _, param := range strings.Split(value[end+1:], ";") {
+			if name, paramValue, ok := strings.Cut(param, "="); ok {
+				if unquoted, err := strconv.Unquote(paramValue); err == nil {
+					paramValue = unquoted
+				}
+				input.params[name] = paramValue
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// parseSignatures parses the members of a Signature header, a structured field dictionary of byte sequences.
+func parseSignatures(header string) map[string][]byte {
+	signatures := map[string][]byte{}
+	for _, member := range splitTopLevel(header) {
+		label, value, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+		if signature, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":")); err == nil {
+			signatures[label] = signature
+		}
+	}
+	return signatures
+}
+
+// splitTopLevel splits a structured field on the commas outside of inner lists and strings.
+func splitTopLevel(field string) []string {
+	var members []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '(' && !quoted:
+			depth++
+		case c == ')' && !quoted:
+			depth--
+		case c == ',' && !quoted && depth == 0:
+			members = append(members, field[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(field[start:]) != "" {
+		members = append(members, field[start:])
+	}
+	return members
+}
@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/types"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWTSettings configures a self-signed JWT token source.
+type JWTSettings struct {
+	// Key is the private key signing the tokens, an RSA, ECDSA P-256 or Ed25519 key,
+	// or any crypto.Signer backed by one, e.g. a key held in a KMS.
+	Key crypto.Signer
+	// Algorithm is the signing algorithm. Defaults to the algorithm of the key:
+	// enums.Rs256, enums.Es256 or enums.EdDsa.
+	Algorithm enums.JwtAlgorithm
+	// KeyID is sent in the "kid" header, so the receiver can select the verification key.
+	KeyID string
+	// Issuer and Subject are the "iss" and "sub" claims, usually the identity of the service.
+	Issuer  string
+	Subject string
+	// Audience is the "aud" claim. Defaults to the scheme and host of each request,
+	// e.g. "https://pubsub.googleapis.com/", and a token is minted per audience.
+	Audience string
+	// Claims are additional claims, e.g. "scope". They do not override the claims above.
+	Claims map[string]any
+	// Lifetime is how long tokens are valid. Defaults to 1 hour.
+	Lifetime time.Duration
+	// RefreshAhead is how long before expiry new tokens are minted. Defaults to 5 minutes,
+	// and is limited to half of Lifetime so that tokens are reused.
+	RefreshAhead time.Duration
+}
+
+// JWT mints self-signed JWTs and authorizes requests with them as bearer tokens. Tokens are
+// cached per audience until they are close to expiry, with a single token minted at a time
+// for every audience. It is safe for concurrent use.
+type JWT struct {
+	settings JWTSettings
+	mu       sync.Mutex
+	tokens   map[string]*Token
+	flights  map[string]*tokenFlight
+}
+
+// ecdsaSignature is the ASN.1 encoding of ECDSA signatures returned by crypto.Signer.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// NewJWT creates a self-signed JWT token source.
+//
+// Parameters:
+// - settings: The JWT settings.
+func NewJWT(settings JWTSettings) *JWT {
+	if settings.Lifetime <= 0 {
+		settings.Lifetime = time.Hour
+	}
+	if settings.RefreshAhead <= 0 {
+		settings.RefreshAhead = 5 * time.Minute
+	}
+	settings.RefreshAhead = min(settings.RefreshAhead, settings.Lifetime/2)
+	return &JWT{settings: settings, tokens: map[string]*Token{}, flights: map[string]*tokenFlight{}}
+}
+
+// Middleware returns the middleware authorizing requests with a token minted for their audience.
+// Register it with network.WithMiddleware, or register the token source itself with network.WithAuthenticator.
+func (j *JWT) Middleware() types.Middleware {
+	return Middleware(j)
+}
+
+// Authenticate sets the Authorization header of the request with a token minted for its audience.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (j *JWT) Authenticate(request *http.Request) error {
+	audience := j.settings.Audience
+	if audience == "" {
+		audience = request.URL.Scheme + "://" + host(request) + "/"
+	}
+	token, err := j.Token(audience)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(constants.Authorization, token.authorization())
+	return nil
+}
+
+// Challenge reports that the request is not sent again, as a new token would be signed with the same key.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (j *JWT) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
+
+// Token returns a valid token for the audience, minting a new one when none is cached or the cached one is close to expiry.
+// Concurrent callers for the same audience share the token being minted.
+//
+// Parameters:
+// - audience: The "aud" claim of the token.
+func (j *JWT) Token(audience string) (*Token, error) {
+	j.mu.Lock()
+	if token := j.tokens[audience]; token != nil && time.Now().Before(token.Expiry.Add(-j.settings.RefreshAhead)) {
+		j.mu.Unlock()
+		return token, nil
+	}
+	flight, minting := j.flights[audience]
+	if !minting {
+		flight = &tokenFlight{done: make(chan struct{})}
+		j.flights[audience] = flight
+	}
+	j.mu.Unlock()
+
+	if !minting {
+		j.mint(audience, flight)
+	}
+	<-flight.done
+	return flight.token, flight.err
+}
+
+// mint mints a token for the audience, caches it and shares it with the callers waiting for the flight.
+// Expired tokens of other audiences are dropped, so the cache does not grow with past audiences.
+// A panic while minting is reported to the waiting callers as an error and propagated to the caller.
+func (j *JWT) mint(audience string, flight *tokenFlight) {
+	defer func() {
+		if r := recover(); r != nil {
+			flight.token, flight.err = nil, fmt.Errorf("minting the JWT panicked: %v", r)
+			defer panic(r)
+		}
+		j.mu.Lock()
+		delete(j.flights, audience)
+		j.mu.Unlock()
+		close(flight.done)
+	}()
+	flight.token, flight.err = j.Mint(audience, time.Now())
+	if flight.err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for cached, token := range j.tokens {
+		if !now.Before(token.Expiry) {
+			delete(j.tokens, cached)
+		}
+	}
+	j.tokens[audience] = flight.token
+}
+
+// Mint signs a new token for the audience, issued at the given time, without caching it.
+//
+// Parameters:
+// - audience: The "aud" claim of the token.
+// - issuedAt: The "iat" claim of the token, from which its lifetime is measured.
+func (j *JWT) Mint(audience string, issuedAt time.Time) (*Token, error) {
+	if j.settings.Key == nil {
+		return nil, fmt.Errorf("no key to sign the JWT")
+	}
+	algorithm := j.settings.Algorithm
+	if algorithm == "" {
+		algorithm = jwtAlgorithm(j.settings.Key.Public())
+	}
+	expiry := issuedAt.Add(j.settings.Lifetime)
+
+	header := map[string]string{"alg": algorithm.String(), "typ": "JWT"}
+	if j.settings.KeyID != "" {
+		header["kid"] = j.settings.KeyID
+	}
+	claims := map[string]any{}
+	for name, value := range j.settings.Claims {
+		claims[name] = value
+	}
+	claims["aud"] = audience
+	claims["iat"] = issuedAt.Unix()
+	claims["exp"] = expiry.Unix()
+	if j.settings.Issuer != "" {
+		claims["iss"] = j.settings.Issuer
+	}
+	if j.settings.Subject != "" {
+		claims["sub"] = j.settings.Subject
+	}
+
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	signature, err := signJWT(j.settings.Key, algorithm, []byte(signingInput))
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		AccessToken: signingInput + "." + base64.RawURLEncoding.EncodeToString(signature),
+		TokenType:   "Bearer",
+		Expiry:      expiry,
+	}, nil
+}
+
+// ParsePrivateKey parses a PEM encoded RSA, ECDSA or Ed25519 private key, in PKCS #8, PKCS #1 or SEC 1 form,
+// such as the private key of a service account.
+//
+// Parameters:
+// - data: The PEM encoded private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded private key")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T cannot sign", key)
+	}
+	return signer, nil
+}
+
+// jwtAlgorithm returns the signing algorithm matching the public key, or an empty algorithm when none does.
+func jwtAlgorithm(public crypto.PublicKey) enums.JwtAlgorithm {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return enums.Rs256
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() {
+			return enums.Es256
+		}
+	case ed25519.PublicKey:
+		return enums.EdDsa
+	}
+	return ""
+}
+
+// signJWT signs the JWS signing input with the key.
+func signJWT(key crypto.Signer, algorithm enums.JwtAlgorithm, signingInput []byte) ([]byte, error) {
+	if algorithm == "" {
+		return nil, fmt.Errorf("unsupported JWT key of type %T", key.Public())
+	}
+	if jwtAlgorithm(key.Public()) != algorithm {
+		return nil, fmt.Errorf("key of type %T cannot sign with %q", key.Public(), algorithm)
+	}
+	if algorithm == enums.EdDsa {
+		return key.Sign(rand.Reader, signingInput, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(signingInput)
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil || algorithm == enums.Rs256 {
+		return signature, err
+	}
+	// ECDSA signatures are the concatenation of r and s, 32 bytes each (RFC 7518 section 3.4)
+	var parsed ecdsaSignature
+	if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+		return nil, err
+	}
+	jws := make([]byte, 64)
+	parsed.R.FillBytes(jws[:32])
+	parsed.S.FillBytes(jws[32:])
+	return jws, nil
+}
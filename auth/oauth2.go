@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"github.com/xander1235/gorest/types"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2Settings configures an OAuth2 token source.
+type OAuth2Settings struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string
+	// Grant is the grant used to obtain tokens. Defaults to enums.ClientCredentials.
+	Grant enums.GrantType
+	// ClientID and ClientSecret identify the client. They are sent with HTTP Basic authentication,
+	// or in the request body when ClientAuthInBody is set or the client has no secret.
+	ClientID     string
+	ClientSecret string
+	// ClientAuthInBody sends the client credentials in the request body instead of the Authorization header.
+	ClientAuthInBody bool
+	// Username and Password are the resource owner credentials of the password grant.
+	Username string
+	Password string
+	// RefreshToken is the refresh token of the refresh token grant.
+	RefreshToken string
+	// Scopes are the scopes requested.
+	Scopes []string
+	// Params are additional parameters sent to the token endpoint, e.g. "audience".
+	Params map[string]string
+	// RefreshAhead is how long before expiry tokens are refreshed in the background. Defaults to 30 seconds.
+	RefreshAhead time.Duration
+	// Client is the HTTP client used for token requests. Defaults to a client with a 30 seconds timeout.
+	Client *http.Client
+}
+
+// Token is an OAuth2 access token.
+type Token struct {
+	// AccessToken is the token sent with requests.
+	AccessToken string `json:"access_token"`
+	// TokenType is the type of the token, usually "Bearer".
+	TokenType string `json:"token_type"`
+	// RefreshToken is the token used to obtain new access tokens, if the server issued one.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// Expiry is the time the token expires. Zero means the token does not expire.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// OAuth2 obtains OAuth2 access tokens and authorizes requests with them. Tokens are cached
+// and refreshed in the background ahead of expiry, with a single token request in flight
+// at a time. It is safe for concurrent use.
+type OAuth2 struct {
+	settings OAuth2Settings
+	mu       sync.Mutex
+	token    *Token
+	// refreshToken is the latest refresh token, kept when the token is invalidated
+	refreshToken string
+	flight       *tokenFlight
+	// failures, lastErr and retryAt delay token requests after consecutive failures
+	failures int
+	lastErr  error
+	retryAt  time.Time
+}
+
+// tokenFlight is a token request in flight whose outcome is shared by every caller.
+type tokenFlight struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// tokenResponse is the body of a successful token response (RFC 6749 section 5.1).
+type tokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	TokenType    string      `json:"token_type"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    json.Number `json:"expires_in"`
+}
+
+// NewOAuth2 creates an OAuth2 token source.
+//
+// Parameters:
+// - settings: The OAuth2 settings.
+func NewOAuth2(settings OAuth2Settings) *OAuth2 {
+	if settings.Grant == "" {
+		settings.Grant = enums.ClientCredentials
+	}
+	if settings.RefreshAhead <= 0 {
+		settings.RefreshAhead = 30 * time.Second
+	}
+	if settings.Client == nil {
+		settings.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &OAuth2{settings: settings, refreshToken: settings.RefreshToken}
+}
+
+// Middleware returns the middleware authorizing requests with an access token.
+// A request answered with 401 Unauthorized is sent once more with a new token when its body can be replayed.
+// Register it with network.WithMiddleware, or register the token source itself with network.WithAuthenticator.
+func (o *OAuth2) Middleware() types.Middleware {
+	return Middleware(o)
+}
+
+// Authenticate sets the Authorization header of the request with a valid access token.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (o *OAuth2) Authenticate(request *http.Request) error {
+	token, err := o.Token(request.Context())
+	if err != nil {
+		return err
+	}
+	request.Header.Set(constants.Authorization, token.authorization())
+	return nil
+}
+
+// Challenge drops the token rejected with 401 Unauthorized, so the request is sent again with a new one.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (o *OAuth2) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	if res.StatusCode != http.StatusUnauthorized || res.Request == nil {
+		return false, nil
+	}
+	o.invalidate(res.Request.Header.Get(constants.Authorization))
+	return true, nil
+}
+
+// Token returns a valid access token, requesting a new one when none is cached or the cached one expired.
+// A token close to expiry is returned while a new one is requested in the background. After a failed
+// token request, no new one is sent until a backoff elapses: the cached token is returned meanwhile
+// while it is still valid, and the error of the failed request otherwise.
+//
+// Parameters:
+// - ctx: The context bounding the wait for a new token.
+func (o *OAuth2) Token(ctx context.Context) (*Token, error) {
+	o.mu.Lock()
+	token := o.token
+	now := time.Now()
+	if token != nil && (token.Expiry.IsZero() || now.Before(token.Expiry.Add(-o.settings.RefreshAhead))) {
+		o.mu.Unlock()
+		return token, nil
+	}
+	if o.flight == nil && now.Before(o.retryAt) {
+		// The last token request failed, so the token endpoint is not called until the next attempt
+		err := o.lastErr
+		o.mu.Unlock()
+		if token != nil && now.Before(token.Expiry) {
+			return token, nil
+		}
+		return nil, err
+	}
+	if o.flight == nil {
+		o.flight = &tokenFlight{done: make(chan struct{})}
+		go o.refresh(context.WithoutCancel(ctx), o.flight, o.refreshToken)
+	}
+	flight := o.flight
+	o.mu.Unlock()
+
+	if token != nil && now.Before(token.Expiry) {
+		return token, nil
+	}
+	select {
+	case <-flight.done:
+		return flight.token, flight.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate drops the cached token if it is the one of the Authorization header value,
+// so the next call to Token requests a new one.
+func (o *OAuth2) invalidate(authorization string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token != nil && o.token.authorization() == authorization {
+		o.token = nil
+	}
+}
+
+// refresh requests a new token and shares the outcome with the callers waiting for the flight.
+// The refresh token is used first when there is one, falling back to the configured grant.
+func (o *OAuth2) refresh(ctx context.Context, flight *tokenFlight, refreshToken string) {
+	var token *Token
+	var err error
+	if refreshToken != "" {
+		token, err = o.request(ctx, enums.RefreshToken, refreshToken)
+	}
+	if o.settings.Grant != enums.RefreshToken && (refreshToken == "" || err != nil) {
+		token, err = o.request(ctx, o.settings.Grant, "")
+	}
+	if token == nil && err == nil {
+		err = &errors.OAuth2Error{Code: "invalid_grant", Description: "no refresh token"}
+	}
+	if err == nil && token.RefreshToken == "" {
+		// Servers may omit the refresh token when it did not change (RFC 6749 section 6)
+		token.RefreshToken = refreshToken
+	}
+
+	o.mu.Lock()
+	if err == nil {
+		o.token = token
+		o.refreshToken = token.RefreshToken
+		o.failures, o.lastErr, o.retryAt = 0, nil, time.Time{}
+	} else {
+		o.failures++
+		o.lastErr = err
+		o.retryAt = time.Now().Add(o.refreshBackoff())
+	}
+	o.flight = nil
+	o.mu.Unlock()
+	flight.token, flight.err = token, err
+	close(flight.done)
+}
+
+// refreshBackoff returns the delay before a token is requested again after the consecutive failures,
+// doubling from 1 second up to a quarter of the refresh ahead duration.
+// It must be called with o.mu held.
+func (o *OAuth2) refreshBackoff() time.Duration {
+	return min(time.Second<<min(o.failures-1, 30), o.settings.RefreshAhead/4)
+}
+
+// request sends a token request to the token endpoint with the given grant.
+func (o *OAuth2) request(ctx context.Context, grant enums.GrantType, refreshToken string) (*Token, error) {
+	form := url.Values{"grant_type": {grant.String()}}
+	switch grant {
+	case enums.RefreshToken:
+		form.Set("refresh_token", refreshToken)
+	case enums.Password:
+		form.Set("username", o.settings.Username)
+		form.Set("password", o.settings.Password)
+	}
+	if len(o.settings.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.settings.Scopes, " "))
+	}
+	for key, value := range o.settings.Params {
+		form.Set(key, value)
+	}
+	clientAuthInBody := o.settings.ClientAuthInBody || o.settings.ClientSecret == ""
+	if clientAuthInBody && o.settings.ClientID != "" {
+		form.Set("client_id", o.settings.ClientID)
+		if o.settings.ClientSecret != "" {
+			form.Set("client_secret", o.settings.ClientSecret)
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.settings.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(constants.ContentType, enums.FormUrlEncoded.ToString())
+	request.Header.Set(constants.Accept, enums.Json.ToString())
+	if !clientAuthInBody {
+		// Credentials are form encoded before being used with Basic authentication (RFC 6749 section 2.3.1)
+		request.SetBasicAuth(url.QueryEscape(o.settings.ClientID), url.QueryEscape(o.settings.ClientSecret))
+	}
+
+	requestTime := time.Now()
+	res, err := o.settings.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(res.Body)
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		oauth2Err := &errors.OAuth2Error{}
+		_ = json.Unmarshal(body, oauth2Err) // The error body is optional
+		oauth2Err.StatusCode = res.StatusCode
+		return nil, oauth2Err
+	}
+	return parseToken(res.Header.Get(constants.ContentType), body, requestTime)
+}
+
+// parseToken parses a token response, in JSON or, as some servers send, form URL encoded.
+func parseToken(contentType string, body []byte, requestTime time.Time) (*Token, error) {
+	var response tokenResponse
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == enums.FormUrlEncoded.ToString() || mediaType == "text/plain" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		response = tokenResponse{
+			AccessToken:  values.Get("access_token"),
+			TokenType:    values.Get("token_type"),
+			RefreshToken: values.Get("refresh_token"),
+			ExpiresIn:    json.Number(values.Get("expires_in")),
+		}
+	} else if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.AccessToken == "" {
+		return nil, &errors.OAuth2Error{StatusCode: http.StatusOK, Description: "token response has no access_token"}
+	}
+
+	token := &Token{
+		AccessToken:  response.AccessToken,
+		TokenType:    response.TokenType,
+		RefreshToken: response.RefreshToken,
+	}
+	if seconds, err := strconv.ParseInt(response.ExpiresIn.String(), 10, 64); err == nil && seconds > 0 {
+		// Expiry is measured from the time the request was sent, to stay on the safe side
+		token.Expiry = requestTime.Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// authorization returns the Authorization header value for the token.
+func (t *Token) authorization() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer " + t.AccessToken
+	}
+	return t.TokenType + " " + t.AccessToken
+}
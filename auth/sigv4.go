@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/types"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sigV4Algorithm is the signing algorithm of AWS Signature Version 4.
+	sigV4Algorithm = "AWS4-HMAC-SHA256"
+	// sigV4TimeFormat is the format of the X-Amz-Date value.
+	sigV4TimeFormat = "20060102T150405Z"
+	// unsignedPayload is the payload hash of requests whose body is not signed.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// sigV4IgnoredHeaders are the headers left out of the signature, as they are commonly changed
+// by proxies or set after signing.
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":     true,
+	"user-agent":        true,
+	"x-amzn-trace-id":   true,
+	"expect":            true,
+	"transfer-encoding": true,
+	"accept-encoding":   true,
+	"connection":        true,
+}
+
+// SigV4Settings configures an AWS Signature Version 4 signer.
+type SigV4Settings struct {
+	// AccessKeyID and SecretAccessKey are the AWS credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the token of temporary credentials, sent in X-Amz-Security-Token.
+	SessionToken string
+	// Region is the AWS region, e.g. "us-east-1".
+	Region string
+	// Service is the signing name of the service, e.g. "execute-api", "es" or "s3".
+	// The "s3" service follows the S3 rules: paths are escaped once and not normalized,
+	// and the payload hash is sent in X-Amz-Content-Sha256.
+	Service string
+	// UnsignedPayload leaves the body out of the signature. It is required for bodies that cannot be
+	// replayed, such as streamed multipart bodies, and is only accepted by some services like S3.
+	UnsignedPayload bool
+}
+
+// SigV4 signs requests with AWS Signature Version 4. It is safe for concurrent use.
+type SigV4 struct {
+	settings SigV4Settings
+}
+
+// NewSigV4 creates an AWS Signature Version 4 signer.
+//
+// Parameters:
+// - settings: The signer settings.
+func NewSigV4(settings SigV4Settings) *SigV4 {
+	return &SigV4{settings: settings}
+}
+
+// Middleware returns the middleware signing requests.
+// Register it with network.WithMiddleware, or register the signer itself with network.WithAuthenticator.
+func (s *SigV4) Middleware() types.Middleware {
+	return Middleware(s)
+}
+
+// Authenticate signs the request at the current time.
+//
+// Parameters:
+// - request: The request to authenticate.
+func (s *SigV4) Authenticate(request *http.Request) error {
+	return s.Sign(request, time.Now())
+}
+
+// Challenge reports that the request is not sent again, as a rejected signature would be rejected again.
+//
+// Parameters:
+// - res: The challenge response.
+// - challenges: The challenges of the response.
+func (s *SigV4) Challenge(res *http.Response, challenges []Challenge) (bool, error) {
+	return false, nil
+}
+
+// Sign signs the request in place, setting the Authorization, X-Amz-Date and, when needed,
+// X-Amz-Security-Token and X-Amz-Content-Sha256 headers. Every header already set on the
+// request is signed, except for a few commonly changed in transit. Bodies without GetBody
+// are read into memory to be hashed, unless UnsignedPayload is set.
+//
+// Parameters:
+// - request: The request to sign.
+// - signingTime: The time of the signature.
+func (s *SigV4) Sign(request *http.Request, signingTime time.Time) error {
+	payloadHash, err := s.payloadHash(request)
+	if err != nil {
+		return err
+	}
+	signingTime = signingTime.UTC()
+	request.Header.Del(constants.Authorization)
+	request.Header.Set("X-Amz-Date", signingTime.Format(sigV4TimeFormat))
+	if s.settings.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.settings.SessionToken)
+	}
+	if s.isS3() {
+		request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalRequest, signedHeaders := s.canonicalRequest(request, payloadHash)
+	scope := s.scope(signingTime)
+	request.Header.Set(constants.Authorization, sigV4Algorithm+" Credential="+s.settings.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+s.signature(signingTime, scope, canonicalRequest))
+	return nil
+}
+
+// Presign returns the URL of the request signed with query parameters, which grants access to
+// the resource until it expires without any other credentials. Only the host header is signed.
+//
+// Parameters:
+// - request: The request to presign.
+// - signingTime: The time of the signature.
+// - expires: How long the URL is valid, up to 7 days.
+func (s *SigV4) Presign(request *http.Request, signingTime time.Time, expires time.Duration) (string, error) {
+	payloadHash := unsignedPayload
+	if !s.isS3() {
+		var err error
+		if payloadHash, err = s.payloadHash(request); err != nil {
+			return "", err
+		}
+	}
+	signingTime = signingTime.UTC()
+	scope := s.scope(signingTime)
+	signed := *request.URL
+	query := signed.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.settings.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", signingTime.Format(sigV4TimeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.settings.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.settings.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		s.canonicalURI(&signed),
+		canonicalQuery(query),
+		"host:" + host(request) + "\n",
+		"host",
+		payloadHash,
+	}, "\n")
+	signed.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(signingTime, scope, canonicalRequest)
+	return signed.String(), nil
+}
+
+// isS3 checks if the requests are signed for S3, which follows its own canonicalization rules.
+func (s *SigV4) isS3() bool {
+	return s.settings.Service == "s3"
+}
+
+// payloadHash returns the hex encoded SHA-256 hash of the request body.
+func (s *SigV4) payloadHash(request *http.Request) (string, error) {
+	if s.settings.UnsignedPayload {
+		return unsignedPayload, nil
+	}
+	body, err := readBody(request)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// scope returns the credential scope of the signature.
+func (s *SigV4) scope(signingTime time.Time) string {
+	return signingTime.Format("20060102") + "/" + s.settings.Region + "/" + s.settings.Service + "/aws4_request"
+}
+
+// canonicalRequest returns the canonical request and the signed headers of the request.
+func (s *SigV4) canonicalRequest(request *http.Request, payloadHash string) (string, string) {
+	canonicalHeaders, signedHeaders := s.canonicalHeaders(request)
+	return strings.Join([]string{
+		request.Method,
+		s.canonicalURI(request.URL),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// stringToSign returns the string to sign of the canonical request.
+func stringToSign(signingTime time.Time, scope string, canonicalRequest string) string {
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	return sigV4Algorithm + "\n" + signingTime.Format(sigV4TimeFormat) + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+}
+
+// signature computes the signature of the canonical request.
+func (s *SigV4) signature(signingTime time.Time, scope string, canonicalRequest string) string {
+	key := hmacSHA256([]byte("AWS4"+s.settings.SecretAccessKey), signingTime.Format("20060102"))
+	key = hmacSHA256(key, s.settings.Region)
+	key = hmacSHA256(key, s.settings.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign(signingTime, scope, canonicalRequest)))
+}
+
+// canonicalURI returns the escaped path of the URL. Except for S3, the path is taken as sent,
+// normalized and escaped again, so its segments end up escaped twice. S3 paths are escaped once as they are.
+func (s *SigV4) canonicalURI(u *url.URL) string {
+	if s.isS3() {
+		if u.Path == "" {
+			return "/"
+		}
+		return uriEncode(u.Path, false)
+	}
+	p := u.EscapedPath()
+	if strings.HasPrefix(u.Opaque, "/") && !strings.HasPrefix(u.Opaque, "//") {
+		// An opaque path is sent verbatim
+		p = u.Opaque
+	}
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return uriEncode(cleaned, false)
+}
+
+// canonicalHeaders returns the canonical headers and the signed headers of the request.
+func (s *SigV4) canonicalHeaders(request *http.Request) (string, string) {
+	values := map[string][]string{"host": {host(request)}}
+	for name, headerValues := range request.Header {
+		name = strings.ToLower(name)
+		if sigV4IgnoredHeaders[name] || name == "host" {
+			continue
+		}
+		for _, value := range headerValues {
+			values[name] = append(values[name], strings.Join(strings.Fields(value), " "))
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + strings.Join(values[name], ",") + "\n")
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// canonicalQuery returns the query parameters escaped and sorted by name and value.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// host returns the host of the request without the default port of its scheme.
+func host(request *http.Request) string {
+	h := request.Host
+	if h == "" {
+		h = request.URL.Host
+	}
+	if request.URL.Scheme == "https" {
+		return strings.TrimSuffix(h, ":443")
+	}
+	return strings.TrimSuffix(h, ":80")
+}
+
+// uriEncode escapes every byte except the unreserved characters of RFC 3986, and slashes unless encodeSlash is set.
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' || (c == '/' && !encodeSlash) {
+			encoded.WriteByte(c)
+			continue
+		}
+		encoded.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return encoded.String()
+}
+
+// hmacSHA256 computes the HMAC-SHA256 of the data with the key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sigV4SuiteToken is the session token of the post-sts-token case of the AWS Signature Version 4 test suite.
+const sigV4SuiteToken = "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTflfKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2ICCR/oLxBA=="
+
+// sigV4SuiteCase is a case of the AWS Signature Version 4 test suite, with the contents of its
+// .req, .creq, .sts and .authz files.
+type sigV4SuiteCase struct {
+	name  string
+	req   string
+	creq  string
+	sts   string
+	authz string
+	token string
+}
+
+var sigV4SuiteCases = []sigV4SuiteCase{
+	{
+		name: "get-vanilla",
+		req: "GET / HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"bb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+	},
+	{
+		name: "get-vanilla-query-order-key",
+		req: "GET /?Param1=value2&Param1=Value1 HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/\nParam1=Value1&Param1=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"704b4cef673542d84cdff252633f065e8daeba5f168b77116f8b1bcaf3d38f89",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=eedbc4e291e521cf13422ffca22be7d2eb8146eecf653089df300a15b2382bd1",
+	},
+	{
+		name: "get-vanilla-query-order-key-case",
+		req: "GET /?Param2=value2&Param1=value1 HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"816cd5b414d056048ba4f7c5386d6e0533120fb1fcfa93762cf0fc39e2cf19e0",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+	},
+	{
+		name: "get-utf8",
+		req: "GET /ሴ HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/%E1%88%B4\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"2a0a97d02205e45ce2e994789806b19270cfbbb0921b278ccf58f5249ac42102",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85",
+	},
+	{
+		name: "get-space",
+		req: "GET /example space/ HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/example%20space/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"63ee75631ed7234ae61b5f736dfc7754cdccfedbff4b5128a915706ee9390d86",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=652487583200325589f1fba4c7e578f72c47cb61beeca81406b39ddec1366741",
+	},
+	{
+		name: "get-slash-dot-slash",
+		req: "GET /./ HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"bb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+	},
+	{
+		name: "post-x-www-form-urlencoded",
+		req: "POST / HTTP/1.1\n" +
+			"Content-Type:application/x-www-form-urlencoded\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n" +
+			"\n" +
+			"Param1=value1",
+		creq: "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+			"content-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"42a5e5bb34198acb3e84da4f085bb7927f2bc277ca766e6d19c73c2154021281",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, " +
+			"Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+	},
+	{
+		name: "post-sts-token",
+		req: "POST / HTTP/1.1\n" +
+			"Host:example.amazonaws.com\n" +
+			"X-Amz-Date:20150830T123600Z\n",
+		creq: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\nx-amz-security-token:" + sigV4SuiteToken + "\n\n" +
+			"host;x-amz-date;x-amz-security-token\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		sts: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+			"c237e1b440d4c63c32ca95b5b99481081cb7b13c7e40434868e71567c1a882f6",
+		authz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, " +
+			"Signature=85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+		token: sigV4SuiteToken,
+	},
+}
+
+func TestSigV4Suite(t *testing.T) {
+	signingTime := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	for _, c := range sigV4SuiteCases {
+		t.Run(c.name, func(t *testing.T) {
+			signer := NewSigV4(SigV4Settings{
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				SessionToken:    c.token,
+				Region:          "us-east-1",
+				Service:         "service",
+			})
+			request := newSuiteRequest(t, c.req)
+			if err := signer.Sign(request, signingTime); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			payloadHash, err := signer.payloadHash(request)
+			if err != nil {
+				t.Fatalf("payloadHash() error = %v", err)
+			}
+			canonicalRequest, _ := signer.canonicalRequest(request, payloadHash)
+			if canonicalRequest != c.creq {
+				t.Errorf("canonical request = %q, want %q", canonicalRequest, c.creq)
+			}
+			if sts := stringToSign(signingTime, signer.scope(signingTime), canonicalRequest); sts != c.sts {
+				t.Errorf("string to sign = %q, want %q", sts, c.sts)
+			}
+			if authz := request.Header.Get("Authorization"); authz != c.authz {
+				t.Errorf("Authorization = %q, want %q", authz, c.authz)
+			}
+		})
+	}
+}
+
+// newSuiteRequest builds the request of a .req file. The path is kept as written, as it is sent on the wire.
+func newSuiteRequest(t *testing.T, raw string) *http.Request {
+	t.Helper()
+	head, body, _ := strings.Cut(raw, "\n\n")
+	reader := bufio.NewReader(strings.NewReader(head))
+	requestLine, _ := reader.ReadString('\n')
+	fields := strings.Fields(requestLine)
+	target := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(requestLine), fields[0]+" "), " HTTP/1.1")
+
+	request, err := http.NewRequest(fields[0], "https://example.amazonaws.com/", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	request.URL = &url.URL{Scheme: "https", Host: "example.amazonaws.com"}
+	request.URL.Opaque, request.URL.RawQuery, _ = strings.Cut(target, "?")
+	request.Header = http.Header{}
+	for {
+		line, err := reader.ReadString('\n')
+		if name, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && name != "Host" && name != "X-Amz-Date" {
+			request.Header.Add(name, value)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return request
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	stderrors "errors"
+	"github.com/xander1235/gorest/auth"
+	"net/http"
+	"net/url"
+)
+
+// WithAuthenticator sets the authenticator of every request made by the client, e.g. auth.NewBasic,
+// auth.NewBearer, auth.NewAPIKeyHeader, auth.NewAPIKeyQuery, auth.NewOAuth2, auth.NewSigV4 or auth.NewDigest.
+// Requests are authenticated before the middleware chain runs, and sent once more when the authenticator
+// accepts the challenge of a 401 or 407 response. Redirects to other hosts or from https to http are not authenticated.
+//
+// Parameters:
+// - authenticator: The authenticator to use.
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return func(nc *networkClient) {
+		nc.authenticator = authenticator
+	}
+}
+
+// Authenticator sets the authenticator for the request, overriding the client's authenticator.
+// A nil authenticator sends the request without credentials.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - authenticator: The authenticator to use.
+func (nc networkClient) Authenticator(authenticator auth.Authenticator) networkClient {
+	nc.authenticator = authenticator
+	return nc
+}
+
+// doAuthenticated executes the request through the middleware chain, authenticated when authenticate is set.
+// A copy of the request is authenticated, so redirects built from the request do not carry the credentials,
+// and credentials the authenticator added to the URL are redacted from the response and error.
+//
+// Parameters:
+// - request: The HTTP request to execute.
+// - authenticate: Whether the request is authenticated.
+func (nc networkClient) doAuthenticated(request *http.Request, authenticate bool) (*http.Response, error) {
+	if nc.authenticator == nil || !authenticate {
+		return nc.do(request)
+	}
+	res, err := auth.Middleware(nc.authenticator)(nc.do)(request.Clone(request.Context()))
+	if redactor, ok := nc.authenticator.(auth.URLRedactor); ok {
+		redactURLs(redactor, res, err)
+	}
+	return res, err
+}
+
+// redactURLs redacts the credentials from the URL of the request of the response and from the URL of the error.
+//
+// Parameters:
+// - redactor: The authenticator that added the credentials.
+// - res: The HTTP response, if any.
+// - err: The error, if any.
+func redactURLs(redactor auth.URLRedactor, res *http.Response, err error) {
+	var urlErr *url.Error
+	if stderrors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = redactor.RedactURL(u).String()
+		}
+	}
+	if res != nil && res.Request != nil && res.Request.URL != nil {
+		res.Request = res.Request.WithContext(res.Request.Context())
+		res.Request.URL = redactor.RedactURL(res.Request.URL)
+	}
+}
@@ -0,0 +1,273 @@
+package network
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState represents the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the open timeout expires.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through.
+	CircuitHalfOpen
+)
+
+// String returns the string representation of the CircuitState.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings configures a CircuitBreaker.
+// Zero values are replaced by the defaults documented on each field.
+type CircuitBreakerSettings struct {
+	// ConsecutiveFailures trips the breaker after this many failures in a row. Defaults to 5.
+	ConsecutiveFailures int
+	// FailureRatio trips the breaker when the ratio of failures in the current window reaches it.
+	// Zero disables ratio based tripping.
+	FailureRatio float64
+	// MinRequests is the number of requests in the current window required before FailureRatio applies. Defaults to 10.
+	MinRequests int
+	// Window is the interval after which the counts of a closed breaker are reset. Defaults to 1 minute.
+	Window time.Duration
+	// OpenTimeout is how long the breaker stays open before probing. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of probe requests allowed while half-open,
+	// all of which must succeed to close the breaker. Defaults to 1.
+	HalfOpenMaxRequests int
+	// IsFailure decides whether an attempt counts as a failure.
+	// Defaults to transport errors and 5xx responses. Attempts canceled through their context
+	// are neither successes nor failures and are not passed to IsFailure.
+	IsFailure func(res *http.Response, err error) bool
+	// OnStateChange is called whenever the breaker of a host changes state.
+	// It is called synchronously and must not call back into the breaker.
+	OnStateChange func(host string, from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker keeps an independent closed/open/half-open breaker for every host.
+// A single CircuitBreaker can be shared between clients so they trip together.
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+	mu       sync.Mutex
+	hosts    map[string]*hostCircuit
+}
+
+// hostCircuit holds the breaker state and counts for a single host.
+type hostCircuit struct {
+	state               CircuitState
+	generation          uint64
+	expiry              time.Time
+	requests            int
+	failures            int
+	consecutiveFailures int
+	halfOpenInFlight    int
+	halfOpenSuccesses   int
+}
+
+// NewCircuitBreaker creates a per-host circuit breaker.
+//
+// Parameters:
+// - settings: The breaker settings.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.ConsecutiveFailures <= 0 {
+		settings.ConsecutiveFailures = 5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 10
+	}
+	if settings.Window <= 0 {
+		settings.Window = time.Minute
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = defaultIsFailure
+	}
+	return &CircuitBreaker{
+		settings: settings,
+		hosts:    map[string]*hostCircuit{},
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker guarding every request made by the client.
+//
+// Parameters:
+// - breaker: The circuit breaker to use.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(nc *networkClient) {
+		nc.breaker = breaker
+	}
+}
+
+// State returns the current state of the breaker for the given host.
+//
+// Parameters:
+// - host: The host, as found in the request URL (e.g. "api.example.com:8443").
+func (cb *CircuitBreaker) State(host string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.circuit(host, time.Now()).state
+}
+
+// defaultIsFailure counts transport errors and 5xx responses as failures.
+func defaultIsFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return enums.HttpStatus(res.StatusCode).Is5XXSeries()
+}
+
+// circuit returns the breaker for the host, moving it out of expired states.
+// It must be called with cb.mu held.
+func (cb *CircuitBreaker) circuit(host string, now time.Time) *hostCircuit {
+	c, ok := cb.hosts[host]
+	if !ok {
+		c = &hostCircuit{expiry: now.Add(cb.settings.Window)}
+		cb.hosts[host] = c
+	}
+	switch c.state {
+	case CircuitClosed:
+		if now.After(c.expiry) {
+			cb.reset(c, now)
+		}
+	case CircuitOpen:
+		if now.After(c.expiry) {
+			cb.setState(host, c, CircuitHalfOpen, now)
+		}
+	}
+	return c
+}
+
+// allow reports whether a request to the host may proceed and returns the generation it belongs to.
+func (cb *CircuitBreaker) allow(host string) (uint64, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(host, time.Now())
+	switch c.state {
+	case CircuitOpen:
+		return c.generation, false
+	case CircuitHalfOpen:
+		if c.halfOpenInFlight >= cb.settings.HalfOpenMaxRequests {
+			return c.generation, false
+		}
+		c.halfOpenInFlight++
+	}
+	c.requests++
+	return c.generation, true
+}
+
+// record records the outcome of a request allowed in the given generation.
+// Outcomes of requests from an earlier generation are ignored.
+func (cb *CircuitBreaker) record(host string, generation uint64, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	c := cb.circuit(host, now)
+	if c.generation != generation {
+		return
+	}
+	switch c.state {
+	case CircuitClosed:
+		if !failed {
+			c.consecutiveFailures = 0
+			return
+		}
+		c.failures++
+		c.consecutiveFailures++
+		if c.consecutiveFailures >= cb.settings.ConsecutiveFailures ||
+			(cb.settings.FailureRatio > 0 && c.requests >= cb.settings.MinRequests &&
+				float64(c.failures)/float64(c.requests) >= cb.settings.FailureRatio) {
+			cb.setState(host, c, CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		c.halfOpenInFlight--
+		if failed {
+			cb.setState(host, c, CircuitOpen, now)
+			return
+		}
+		c.halfOpenSuccesses++
+		if c.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests {
+			cb.setState(host, c, CircuitClosed, now)
+		}
+	}
+}
+
+// release releases a request allowed in the given generation that was canceled, which counts
+// neither as a success nor as a failure, so a canceled probe does not close a half-open breaker.
+// Requests from an earlier generation are ignored.
+func (cb *CircuitBreaker) release(host string, generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(host, time.Now())
+	if c.generation == generation && c.state == CircuitHalfOpen {
+		c.halfOpenInFlight--
+	}
+}
+
+// setState moves the breaker to a new state, starting a new generation.
+// It must be called with cb.mu held.
+func (cb *CircuitBreaker) setState(host string, c *hostCircuit, state CircuitState, now time.Time) {
+	from := c.state
+	c.state = state
+	cb.reset(c, now)
+	if state == CircuitOpen {
+		c.expiry = now.Add(cb.settings.OpenTimeout)
+	}
+	if cb.settings.OnStateChange != nil {
+		cb.settings.OnStateChange(host, from, state)
+	}
+}
+
+// reset clears the counts of the breaker and starts a new generation.
+func (cb *CircuitBreaker) reset(c *hostCircuit, now time.Time) {
+	c.generation++
+	c.requests = 0
+	c.failures = 0
+	c.consecutiveFailures = 0
+	c.halfOpenInFlight = 0
+	c.halfOpenSuccesses = 0
+	c.expiry = now.Add(cb.settings.Window)
+}
+
+// doWithBreaker executes a single attempt of the request, guarded by the client's circuit breaker.
+// Requests to a host with an open breaker fail with an *errors.CircuitOpenError.
+func (nc networkClient) doWithBreaker(request *http.Request) (*http.Response, error) {
+	if nc.breaker == nil {
+		return nc.doDecompressed(request)
+	}
+	host := request.URL.Host
+	generation, ok := nc.breaker.allow(host)
+	if !ok {
+		closeRequestBody(request)
+		return nil, &errors.CircuitOpenError{Host: host}
+	}
+	res, err := nc.doDecompressed(request)
+	if err != nil && stderrors.Is(err, context.Canceled) {
+		nc.breaker.release(host, generation)
+		return res, err
+	}
+	nc.breaker.record(host, generation, nc.breaker.settings.IsFailure(res, err))
+	return res, err
+}
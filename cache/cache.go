@@ -0,0 +1,352 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/xander1235/gorest/types"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XCache is the response header reporting how the cache served a response:
+// "HIT", "MISS", "REVALIDATED" or "STALE".
+const XCache = "X-Cache"
+
+// Settings configures a Cache.
+type Settings struct {
+	// Store persists the cached responses. Defaults to an LRUStore of 1000 entries.
+	Store Store
+	// MaxBodySize is the size of the largest response body that is stored. Defaults to 10 MiB,
+	// and a negative size stores any size.
+	MaxBodySize int64
+	// CredentialHeaders are request headers carrying credentials in addition to Authorization,
+	// Proxy-Authorization and Cookie, e.g. the header of an auth.APIKeyHeader authenticator.
+	// Responses are stored apart for every value of the credential headers.
+	CredentialHeaders []string
+}
+
+// credentialHeaders are the request headers that always carry credentials.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// bypassKey is the context key marking requests that skip the cache.
+type bypassKey struct{}
+
+// Cache is a private HTTP cache following RFC 9111. It honors Cache-Control, Expires,
+// Vary, revalidation with ETag and Last-Modified through conditional requests,
+// stale-while-revalidate and stale-if-error. Only GET responses are stored, and
+// successful unsafe requests invalidate the stored response for their URL.
+// Responses to requests with an Authorization header are only stored when they are
+// explicitly cacheable, and responses to requests with credentials are stored apart
+// for every credential, so a Cache can be shared between clients.
+type Cache struct {
+	settings     Settings
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+// New creates an HTTP cache.
+//
+// Parameters:
+// - settings: The cache settings.
+func New(settings Settings) *Cache {
+	if settings.Store == nil {
+		settings.Store = NewLRUStore(1000)
+	}
+	if settings.MaxBodySize == 0 {
+		settings.MaxBodySize = 10 << 20
+	}
+	return &Cache{
+		settings:     settings,
+		revalidating: map[string]bool{},
+	}
+}
+
+// Middleware returns the middleware serving requests from the cache.
+// Register it with network.WithCache or network.WithMiddleware.
+func (c *Cache) Middleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			return c.handle(next, request)
+		}
+	}
+}
+
+// WithoutCache returns a copy of the context whose requests skip the cache, e.g. streaming
+// requests whose bodies are read as they arrive rather than buffered.
+//
+// Parameters:
+// - ctx: The context of the requests.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// key returns the store key of the request. Requests with credentials are keyed by
+// a hash of their credential headers, so responses are never shared between credentials.
+func (c *Cache) key(request *http.Request) string {
+	k := http.MethodGet + " " + request.URL.String()
+	hash := sha256.New()
+	credentials := false
+	for _, name := range slices.Concat(credentialHeaders, c.settings.CredentialHeaders) {
+		for _, value := range request.Header.Values(name) {
+			credentials = true
+			hash.Write([]byte(http.CanonicalHeaderKey(name) + ": " + value + "\n"))
+		}
+	}
+	if credentials {
+		k += " " + hex.EncodeToString(hash.Sum(nil))
+	}
+	return k
+}
+
+// handle serves the request from the cache, or sends it and stores the response.
+func (c *Cache) handle(next types.Handler, request *http.Request) (*http.Response, error) {
+	if bypass, _ := request.Context().Value(bypassKey{}).(bool); bypass {
+		return next(request)
+	}
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		res, err := next(request)
+		if err == nil && res.StatusCode < 400 {
+			// Unsafe methods invalidate the stored response (RFC 9111 section 4.4),
+			// the one stored without credentials and the one stored for the request credentials
+			c.settings.Store.Delete(http.MethodGet + " " + request.URL.String())
+			c.settings.Store.Delete(c.key(request))
+		}
+		return res, err
+	}
+
+	requestControl := parseCacheControl(request.Header)
+	entry, ok := c.settings.Store.Get(c.key(request))
+	if ok && !entry.matches(request) {
+		ok = false
+	}
+	if !ok || requestControl.has("no-store") {
+		if requestControl.has("only-if-cached") {
+			return gatewayTimeout(request), nil
+		}
+		return c.fetch(next, request, "MISS")
+	}
+
+	now := time.Now()
+	responseControl := parseCacheControl(entry.Header)
+	lifetime := entry.freshnessLifetime()
+	age := entry.age(now)
+	if maxAge, ok := requestControl.seconds("max-age"); ok {
+		lifetime = min(lifetime, maxAge)
+	}
+	mustRevalidate := requestControl.has("no-cache") || responseControl.has("no-cache")
+	if !mustRevalidate && age < lifetime {
+		return entry.response(request, age, "HIT"), nil
+	}
+
+	canServeStale := !responseControl.has("must-revalidate") && !responseControl.has("no-cache")
+	staleness := age - lifetime
+	if window, ok := responseControl.seconds("stale-while-revalidate"); ok && canServeStale && !mustRevalidate && staleness < window {
+		c.revalidateInBackground(next, request, entry)
+		return entry.response(request, age, "STALE"), nil
+	}
+
+	res, err := c.revalidate(next, request, entry)
+	if err != nil || res.StatusCode >= 500 {
+		window, ok := requestControl.seconds("stale-if-error")
+		if !ok {
+			window, ok = responseControl.seconds("stale-if-error")
+		}
+		if ok && canServeStale && staleness < window {
+			if res != nil {
+				discard(res)
+			}
+			return entry.response(request, age, "STALE"), nil
+		}
+	}
+	return res, err
+}
+
+// fetch sends the request and stores the response if it is storable.
+func (c *Cache) fetch(next types.Handler, request *http.Request, status string) (*http.Response, error) {
+	requestTime := time.Now()
+	res, err := next(request)
+	if err != nil || request.Method != http.MethodGet || !isStorable(request, res) {
+		return res, err
+	}
+	if err = c.store(request, res, requestTime); err != nil {
+		return nil, err
+	}
+	res.Header.Set(XCache, status)
+	return res, nil
+}
+
+// revalidate sends a conditional request for the stored response.
+// A 304 Not Modified refreshes the stored response, which is then served.
+func (c *Cache) revalidate(next types.Handler, request *http.Request, entry *Entry) (*http.Response, error) {
+	conditional := request.Clone(request.Context())
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		conditional.Header.Set("If-Modified-Since", lastModified)
+	}
+	if conditional.Header.Get("If-None-Match") == "" && conditional.Header.Get("If-Modified-Since") == "" {
+		return c.fetch(next, request, "MISS")
+	}
+
+	requestTime := time.Now()
+	res, err := next(conditional)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusNotModified {
+		if isStorable(request, res) {
+			if err = c.store(request, res, requestTime); err != nil {
+				return nil, err
+			}
+			res.Header.Set(XCache, "MISS")
+		} else {
+			c.settings.Store.Delete(c.key(request))
+		}
+		return res, nil
+	}
+	discard(res)
+
+	// Update the stored headers with the ones of the 304 response (RFC 9111 section 4.3.4)
+	refreshed := *entry
+	refreshed.Header = entry.Header.Clone()
+	for name, values := range res.Header {
+		if name != "Content-Length" {
+			refreshed.Header[name] = values
+		}
+	}
+	refreshed.RequestTime = requestTime
+	refreshed.ResponseTime = time.Now()
+	c.settings.Store.Set(c.key(request), &refreshed)
+	return refreshed.response(request, refreshed.age(time.Now()), "REVALIDATED"), nil
+}
+
+// revalidateInBackground revalidates the stored response without blocking the request,
+// making sure only one revalidation per key runs at a time.
+func (c *Cache) revalidateInBackground(next types.Handler, request *http.Request, entry *Entry) {
+	k := c.key(request)
+	c.mu.Lock()
+	if c.revalidating[k] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[k] = true
+	c.mu.Unlock()
+
+	background := request.Clone(context.WithoutCancel(request.Context()))
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, k)
+			c.mu.Unlock()
+		}()
+		if res, err := c.revalidate(next, background, entry); err == nil {
+			discard(res)
+		}
+	}()
+}
+
+// store reads the response body and stores the response, unless it is larger than MaxBodySize.
+// The response body is replaced so it can still be read by the caller.
+func (c *Cache) store(request *http.Request, res *http.Response, requestTime time.Time) error {
+	reader := io.Reader(res.Body)
+	if c.settings.MaxBodySize > 0 {
+		reader = io.LimitReader(res.Body, c.settings.MaxBodySize+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		_ = res.Body.Close() // Intentionally ignoring error as the response is abandoned
+		return err
+	}
+	if c.settings.MaxBodySize > 0 && int64(len(body)) > c.settings.MaxBodySize {
+		// Too large to store, hand the rest of the body to the caller unread
+		res.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+		return nil
+	}
+	_ = res.Body.Close() // Intentionally ignoring error as the body was read completely
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &Entry{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header.Clone(),
+		Body:         body,
+		RequestTime:  requestTime,
+		ResponseTime: time.Now(),
+	}
+	if vary := res.Header.Values("Vary"); len(vary) > 0 {
+		entry.Vary = map[string]string{}
+		for _, names := range vary {
+			for _, name := range strings.Split(names, ",") {
+				name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+				entry.Vary[name] = request.Header.Get(name)
+			}
+		}
+	}
+	c.settings.Store.Set(c.key(request), entry)
+	return nil
+}
+
+// matches checks if the request headers named by Vary match the ones of the stored response.
+func (e *Entry) matches(request *http.Request) bool {
+	for name, value := range e.Vary {
+		if request.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// response creates an HTTP response for the request from the stored response.
+func (e *Entry) response(request *http.Request, age time.Duration, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	header.Set(XCache, status)
+	body := e.Body
+	if request.Method == http.MethodHead {
+		body = nil
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
+
+// gatewayTimeout creates the 504 response for only-if-cached requests that are not stored.
+func gatewayTimeout(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "504 " + http.StatusText(http.StatusGatewayTimeout),
+		StatusCode: http.StatusGatewayTimeout,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{XCache: []string{"MISS"}},
+		Body:       http.NoBody,
+		Request:    request,
+	}
+}
+
+// discard reads and closes the body of a response that is not returned.
+func discard(res *http.Response) {
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close() // Intentionally ignoring error as the response is discarded
+}
+
+// readCloser combines a reader with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the parsed directives of a Cache-Control header.
+type cacheControl map[string]string
+
+// parseCacheControl parses the Cache-Control directives of the header.
+// Directive names are lower-cased and quoted values are unquoted.
+func parseCacheControl(header http.Header) cacheControl {
+	directives := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+	return directives
+}
+
+// has checks if the directive is present.
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds returns the value of a delta-seconds directive.
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// heuristicStatuses are the status codes that are cacheable by default (RFC 9110 section 15.1).
+var heuristicStatuses = map[int]bool{
+	200: true, 203: true, 204: true, 206: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// freshnessLifetime returns how long the stored response is fresh (RFC 9111 section 4.2.1).
+func (e *Entry) freshnessLifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+	date := e.date()
+	if expires := e.Header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid dates, such as "0", represent a time in the past
+			return 0
+		}
+		return max(expiresAt.Sub(date), 0)
+	}
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && heuristicStatuses[e.StatusCode] {
+		// A heuristic lifetime of 10% of the time since the last modification
+		return max(date.Sub(lastModified)/10, 0)
+	}
+	return 0
+}
+
+// date returns the Date of the stored response, or the time it was received.
+func (e *Entry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// age returns the current age of the stored response (RFC 9111 section 4.2.3).
+func (e *Entry) age(now time.Time) time.Duration {
+	apparentAge := max(e.ResponseTime.Sub(e.date()), 0)
+	ageValue := time.Duration(0)
+	if seconds, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+	correctedAgeValue := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparentAge, correctedAgeValue) + now.Sub(e.ResponseTime)
+}
+
+// isStorable checks if the response may be stored by a private cache (RFC 9111 section 3).
+func isStorable(request *http.Request, res *http.Response) bool {
+	if request.Method != http.MethodGet {
+		return false
+	}
+	if parseCacheControl(request.Header).has("no-store") {
+		return false
+	}
+	cc := parseCacheControl(res.Header)
+	if cc.has("no-store") || res.Header.Get("Vary") == "*" {
+		return false
+	}
+	if request.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+		// Responses to authenticated requests must be explicitly cacheable (RFC 9111 section 3.5)
+		return false
+	}
+	if _, ok := cc.seconds("max-age"); ok {
+		return true
+	}
+	if res.Header.Get("Expires") != "" || cc.has("public") || cc.has("no-cache") {
+		return true
+	}
+	return heuristicStatuses[res.StatusCode] &&
+		(res.Header.Get("Last-Modified") != "" || res.Header.Get("ETag") != "")
+}
@@ -0,0 +1,103 @@
+// cache package contains the HTTP response cache.
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry is a stored HTTP response.
+// Its fields are exported so stores can serialize entries, e.g. to disk or Redis.
+type Entry struct {
+	// StatusCode is the HTTP status code of the stored response.
+	StatusCode int `json:"status_code"`
+	// Header holds the headers of the stored response.
+	Header http.Header `json:"header"`
+	// Body holds the body of the stored response.
+	Body []byte `json:"body"`
+	// Vary holds the values of the request headers named by the Vary response header.
+	Vary map[string]string `json:"vary,omitempty"`
+	// RequestTime is the time the request that produced the response was sent.
+	RequestTime time.Time `json:"request_time"`
+	// ResponseTime is the time the response was received.
+	ResponseTime time.Time `json:"response_time"`
+}
+
+// Store persists cache entries by key.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry stored for the key, if any.
+	Get(key string) (*Entry, bool)
+	// Set stores the entry for the key, replacing any previous entry.
+	Set(key string, entry *Entry)
+	// Delete removes the entry stored for the key, if any.
+	Delete(key string)
+}
+
+// LRUStore is an in-memory Store evicting the least recently used entries
+// once it holds more than its maximum number of entries.
+type LRUStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// lruItem is an entry together with its key, as kept in the recency list.
+type lruItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewLRUStore creates an in-memory LRU store.
+//
+// Parameters:
+// - maxEntries: The maximum number of entries kept. Values below 1 are treated as 1.
+func NewLRUStore(maxEntries int) *LRUStore {
+	return &LRUStore{
+		maxEntries: max(maxEntries, 1),
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry stored for the key and marks it as recently used.
+func (s *LRUStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*lruItem).entry, true
+}
+
+// Set stores the entry for the key, evicting the least recently used entry if the store is full.
+func (s *LRUStore) Set(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*lruItem).entry = entry
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruItem{key: key, entry: entry})
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Delete removes the entry stored for the key.
+func (s *LRUStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
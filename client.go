@@ -3,8 +3,12 @@ package network
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	stderrors "errors"
 	"github.com/google/uuid"
+	"github.com/xander1235/gorest/auth"
+	"github.com/xander1235/gorest/codecs"
 	"github.com/xander1235/gorest/constants"
 	"github.com/xander1235/gorest/constants/enums"
 	"github.com/xander1235/gorest/exceptions"
@@ -21,28 +25,50 @@ import (
 )
 
 var (
-	NetworkClient = &networkClient{
+	// NetworkClient is the default client, kept for backward compatibility.
+	// Prefer New to create independent clients with their own configuration.
+	NetworkClient = New()
+)
+
+// New creates an independent networkClient configured with the given options.
+// Clients created by New do not share state with NetworkClient or with each other.
+//
+// Example:
+// payments := New(WithHost("https://payments.example.com"), WithHTTPClient(&http.Client{Timeout: 5 * time.Second}))
+// err := payments.Response(&out).Get("/charges/1")
+//
+// Parameters:
+// - opts: The options to apply to the client.
+func New(opts ...Option) *networkClient {
+	nc := &networkClient{
 		client: &http.Client{
 			Timeout: time.Second * 100,
 		},
 		parser:      parsers.ParseResponse,
 		errorParser: parsers.ParseError,
 		requestType: enums.Json.ToString(),
+		flights:     &flightGroup{flights: map[string]*flight{}},
+		codecs:      codecs.NewDefaultRegistry(),
 	}
-)
+	for _, opt := range opts {
+		opt(nc)
+	}
+	if nc.tlsConfig != nil {
+		nc.client = withTLSConfig(nc.client, nc.tlsConfig)
+	}
+	return nc
+}
 
 // NewApmWrapped initializes the networkClient with a wrapped HTTP client.
 // This allows for custom HTTP client configurations.
 //
+// Deprecated: NewApmWrapped replaces the global NetworkClient and is not safe for
+// concurrent use. Use New(WithHTTPClient(wrappedClient)) instead.
+//
 // Parameters:
 // - wrappedClient: A custom HTTP client to use.
 func NewApmWrapped(wrappedClient *http.Client) {
-	NetworkClient = &networkClient{
-		client:      wrappedClient,
-		parser:      parsers.ParseResponse,
-		errorParser: parsers.ParseError,
-		requestType: enums.Json.ToString(),
-	}
+	NetworkClient = New(WithHTTPClient(wrappedClient))
 }
 
 // networkClient is a custom HTTP client that provides methods for making HTTP requests with configurable headers, parameters, and body.
@@ -53,17 +79,36 @@ func NewApmWrapped(wrappedClient *http.Client) {
 // client.Headers(map[string]string{"Authorization": "Bearer token"})
 // response := client.Get("/api/resource")
 type networkClient struct {
-	client      *http.Client
-	headers     map[string]string
-	params      map[string]string
-	host        string
-	body        any
-	multipart   *types.MultipartBody
-	parser      func(string, any) *errors.ErrorDetails
-	errorParser func(string) *errors.ErrorDetails
-	response    any
-	requestType string
-	ctx         context.Context
+	client               *http.Client
+	defaultHeaders       map[string]string
+	headers              map[string]string
+	params               map[string]string
+	host                 string
+	body                 any
+	multipart            *types.MultipartBody
+	parser               func(string, any) *errors.ErrorDetails
+	errorParser          func(string) *errors.ErrorDetails
+	response             any
+	requestType          string
+	ctx                  context.Context
+	retryPolicy          *RetryPolicy
+	breaker              *CircuitBreaker
+	limiter              *RateLimiter
+	middleware           []types.Middleware
+	streamMultipart      bool
+	streamFormat         enums.StreamFormat
+	hedgePolicy          *HedgePolicy
+	coalesce             bool
+	coalesceHeaders      []string
+	flights              *flightGroup
+	compression          *Compression
+	disableDecompression bool
+	redirectPolicy       *RedirectPolicy
+	codecs               *codecs.Registry
+	responseType         string
+	customParser         bool
+	authenticator        auth.Authenticator
+	tlsConfig            *tls.Config
 }
 
 // Response sets the response for the networkClient.
@@ -124,6 +169,21 @@ func (nc networkClient) Body(body any) networkClient {
 func (nc networkClient) MultipartBody(multipart *types.MultipartBody) networkClient {
 	nc.multipart = multipart
 	nc.requestType = enums.Multipart.ToString()
+	nc.streamMultipart = false
+	return nc
+}
+
+// StreamMultipartBody sets a multipart body that is streamed while the request is transmitted
+// instead of being buffered in memory. The Content-Length is sent when the size of every part
+// is known in advance, otherwise the body is sent with chunked encoding.
+// Streamed bodies cannot be replayed, so the request is not retried.
+// This method is chainable and updates the request type to multipart.
+//
+// Parameters:
+// - multipart: The multipart body to set.
+func (nc networkClient) StreamMultipartBody(multipart *types.MultipartBody) networkClient {
+	nc = nc.MultipartBody(multipart)
+	nc.streamMultipart = true
 	return nc
 }
 
@@ -138,12 +198,14 @@ func (nc networkClient) RequestType(requestType enums.RequestType) networkClient
 }
 
 // Parser sets the function used to parse responses.
+// A custom parser decodes every successful response, whatever its Content-Type.
 // This method is chainable and returns the updated networkClient.
 //
 // Parameters:
 // - parser: The function to parse responses.
 func (nc networkClient) Parser(parser func(string, any) *errors.ErrorDetails) networkClient {
 	nc.parser = parser
+	nc.customParser = true
 	return nc
 }
 
@@ -173,7 +235,8 @@ func (nc networkClient) WithContext(ctx context.Context) networkClient {
 // Parameters:
 // - endpoint: The endpoint to send the request to.
 func (nc networkClient) Put(endpoint string) *errors.ErrorDetails {
-	return nc.send(enums.PUT, endpoint)
+	_, appErr := nc.send(enums.PUT, endpoint)
+	return appErr
 }
 
 // Delete sends a DELETE request to the specified endpoint.
@@ -182,7 +245,8 @@ func (nc networkClient) Put(endpoint string) *errors.ErrorDetails {
 // Parameters:
 // - endpoint: The endpoint to send the request to.
 func (nc networkClient) Delete(endpoint string) *errors.ErrorDetails {
-	return nc.send(enums.DELETE, endpoint)
+	_, appErr := nc.send(enums.DELETE, endpoint)
+	return appErr
 }
 
 // Patch sends a PATCH request to the specified endpoint.
@@ -191,7 +255,8 @@ func (nc networkClient) Delete(endpoint string) *errors.ErrorDetails {
 // Parameters:
 // - endpoint: The endpoint to send the request to.
 func (nc networkClient) Patch(endpoint string) *errors.ErrorDetails {
-	return nc.send(enums.PATCH, endpoint)
+	_, appErr := nc.send(enums.PATCH, endpoint)
+	return appErr
 }
 
 // Post sends a POST request to the specified endpoint.
@@ -200,7 +265,8 @@ func (nc networkClient) Patch(endpoint string) *errors.ErrorDetails {
 // Parameters:
 // - endpoint: The endpoint to send the request to.
 func (nc networkClient) Post(endpoint string) *errors.ErrorDetails {
-	return nc.send(enums.POST, endpoint)
+	_, appErr := nc.send(enums.POST, endpoint)
+	return appErr
 }
 
 // Get sends a GET request to the specified endpoint.
@@ -209,87 +275,204 @@ func (nc networkClient) Post(endpoint string) *errors.ErrorDetails {
 // Parameters:
 // - endpoint: The endpoint to send the request to.
 func (nc networkClient) Get(endpoint string) *errors.ErrorDetails {
-	return nc.send(enums.GET, endpoint)
+	_, appErr := nc.send(enums.GET, endpoint)
+	return appErr
+}
+
+// Execute sends an HTTP request with the given method to the specified endpoint and returns the response.
+// The response is returned whenever the server replied, including for 4xx and 5xx statuses
+// alongside the error, so the status, headers and raw body can always be inspected.
+// A successful body is also decoded into the target set with Response.
+//
+// Parameters:
+// - method: The HTTP method to use (GET, POST, etc.).
+// - endpoint: The endpoint to send the request to.
+func (nc networkClient) Execute(method enums.HttpMethods, endpoint string) (*Response, *errors.ErrorDetails) {
+	return nc.send(method, endpoint)
 }
 
 // Send sends an HTTP request based on the configured parameters and body.
-// It determines the request type and calls the appropriate method to send the request.
+// It builds the request for the request type and sends it.
+//
+// Parameters:
+// - method: The HTTP method to use (GET, POST, etc.).
+// - endpoint: The endpoint to send the request to.
+func (nc networkClient) send(method enums.HttpMethods, endpoint string) (*Response, *errors.ErrorDetails) {
+	request, appErr := nc.newRequest(method, endpoint)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return nc.sendRequest(request)
+}
+
+// NewRequest builds the HTTP request based on the configured parameters and body.
+// It determines the request type and calls the appropriate method to build the request,
+// then sets the headers, query parameters and context.
 //
 // Parameters:
 // - method: The HTTP method to use (GET, POST, etc.).
 // - endpoint: The endpoint to send the request to.
-func (nc networkClient) send(method enums.HttpMethods, endpoint string) *errors.ErrorDetails {
+func (nc networkClient) newRequest(method enums.HttpMethods, endpoint string) (*http.Request, *errors.ErrorDetails) {
+	var request *http.Request
+	var appErr *errors.ErrorDetails
 	switch nc.requestType {
 	case enums.Json.ToString():
-		return nc.sendJson(method, endpoint)
+		request, appErr = nc.newJsonRequest(method, endpoint)
 	case enums.Multipart.ToString():
-		return nc.sendMultipart(method, endpoint)
+		nc, request, appErr = nc.newMultipartRequest(method, endpoint)
 	case enums.FormUrlEncoded.ToString():
-		return nc.sendFormUrlEncoded(method, endpoint)
+		request, appErr = nc.newFormUrlEncodedRequest(method, endpoint)
 	default:
-		return exceptions.GenericException(constants.InvalidRequestType, constants.InvalidRequestType, 500)
+		codec, ok := nc.lookupCodec(nc.requestType)
+		if !ok {
+			return nil, exceptions.GenericException(constants.InvalidRequestType, constants.InvalidRequestType, 500)
+		}
+		request, appErr = nc.newCodecRequest(method, endpoint, codec)
+	}
+	if appErr != nil {
+		return nil, appErr
 	}
+	request = nc.prepareRequest(request)
+	if nc.requestType != enums.Multipart.ToString() {
+		if err := nc.compressBody(request); err != nil {
+			return nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+		}
+	}
+	return request, nil
 }
 
-// SendJson sends a JSON request to the specified endpoint.
-// This method is called by the send method when the request type is JSON.
+// NewJsonRequest builds a JSON request to the specified endpoint.
+// This method is called by the newRequest method when the request type is JSON.
 //
 // Parameters:
 // - method: The HTTP method to use.
 // - endpoint: The endpoint to send the request to.
-func (nc networkClient) sendJson(method enums.HttpMethods, endpoint string) *errors.ErrorDetails {
+func (nc networkClient) newJsonRequest(method enums.HttpMethods, endpoint string) (*http.Request, *errors.ErrorDetails) {
 	var jsonBytes buffer.Buffer
 	var marshalErr error
 	if nc.body != nil {
 		marshalErr = json.NewEncoder(&jsonBytes).Encode(nc.body)
 		if marshalErr != nil {
-			return exceptions.GenericException(marshalErr.Error(), constants.SomethingWentWrong, 500)
+			return nil, exceptions.GenericException(marshalErr.Error(), constants.SomethingWentWrong, 500)
 		}
 	}
 	request, err := http.NewRequest(method.String(), nc.host+endpoint, bytes.NewBuffer(jsonBytes.Bytes()))
 
 	if err != nil {
-		return exceptions.GenericException(constants.SomethingWentWrong, err.Error(), 500)
+		return nil, exceptions.GenericException(constants.SomethingWentWrong, err.Error(), 500)
 	}
 
-	return nc.sendRequest(request)
+	return request, nil
 
 }
 
-// SendMultipart sends a multipart request to the specified endpoint.
-// This method is called by the send method when the request type is multipart.
+// NewMultipartRequest builds a multipart request to the specified endpoint.
+// This method is called by the newRequest method when the request type is multipart.
+// It returns the networkClient with the request type updated to the multipart content type.
 //
 // Parameters:
 // - method: The HTTP method to use.
 // - endpoint: The endpoint to send the request to.
-func (nc networkClient) sendMultipart(method enums.HttpMethods, endpoint string) *errors.ErrorDetails {
-	var jsonBytes *bytes.Buffer
+func (nc networkClient) newMultipartRequest(method enums.HttpMethods, endpoint string) (networkClient, *http.Request, *errors.ErrorDetails) {
+	if nc.streamMultipart && nc.multipart != nil {
+		return nc.newStreamingMultipartRequest(method, endpoint)
+	}
+	var jsonBytes = &bytes.Buffer{}
 	var err error
 	if nc.multipart != nil {
 		jsonBytes, nc.requestType, err = nc.multipart.CreateBuffer()
 		if err != nil {
-			return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+			return nc, nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
 		}
 	}
-	request, err := http.NewRequest(method.String(), nc.host+endpoint, bytes.NewBuffer(jsonBytes.Bytes()))
+	request, err := http.NewRequest(method.String(), nc.host+endpoint, bytes.NewReader(jsonBytes.Bytes()))
 	if err != nil {
 		//configs.Sugar.Error(constants.SomethingWentWrongDownstream + err.Error())
-		return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+		return nc, nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
 	}
 
-	return nc.sendRequest(request)
+	return nc, request, nil
 }
 
-// SendRequest sends the actual HTTP request and handles the response.
-// It sets the headers and query parameters before executing the request.
+// NewStreamingMultipartRequest builds a multipart request whose body is written while it is sent.
+// This method is called by the newMultipartRequest method when the multipart body is streamed.
 //
 // Parameters:
-// - request: The HTTP request to send.
-func (nc networkClient) sendRequest(request *http.Request) *errors.ErrorDetails {
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+func (nc networkClient) newStreamingMultipartRequest(method enums.HttpMethods, endpoint string) (networkClient, *http.Request, *errors.ErrorDetails) {
+	var body io.ReadCloser
+	var contentLength int64
+	var err error
+	body, nc.requestType, contentLength, err = nc.multipart.CreateStream()
+	if err != nil {
+		return nc, nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+	}
+	request, err := http.NewRequest(method.String(), nc.host+endpoint, body)
+	if err != nil {
+		_ = body.Close() // Intentionally ignoring error as the request is abandoned
+		return nc, nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+	}
+	if contentLength >= 0 {
+		request.ContentLength = contentLength
+	}
+
+	return nc, request, nil
+}
+
+// NewFormUrlEncodedRequest builds a form URL encoded request to the specified endpoint.
+// This method is called by the newRequest method when the request type is form URL encoded.
+//
+// Parameters:
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+func (nc networkClient) newFormUrlEncodedRequest(method enums.HttpMethods, endpoint string) (*http.Request, *errors.ErrorDetails) {
+	data := url.Values{}
+
+	for k, v := range nc.body.(map[string]string) {
+		data.Set(k, v)
+	}
+
+	// Encode the form data into a URL-encoded string
+	encodedData := data.Encode()
+
+	// Create a new HTTP request with the encoded data as the body
+	request, err := http.NewRequest(method.String(), nc.host+endpoint, strings.NewReader(encodedData))
+	if err != nil {
+		//configs.Sugar.Error(constants.SomethingWentWrongDownstream + err.Error())
+		return nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+	}
+
+	return request, nil
+
+}
+
+// PrepareRequest sets the headers, query parameters and context of the request.
+//
+// Parameters:
+// - request: The HTTP request to prepare.
+func (nc networkClient) prepareRequest(request *http.Request) *http.Request {
 	request.Header = http.Header{
 		constants.ContentType: []string{nc.requestType},
 		constants.XRequestId:  []string{uuid.New().String()},
 	}
+	if accept := nc.accept(); accept != "" && nc.response != nil {
+		request.Header.Set(constants.Accept, accept)
+	}
+	for key, value := range nc.defaultHeaders {
+		request.Header.Set(key, value)
+	}
+	// Per-request headers replace the default headers and the negotiated Accept, whatever the case of their keys
+	replaced := make(map[string]bool, len(nc.defaultHeaders)+1)
+	replaced[constants.Accept] = true
+	for key := range nc.defaultHeaders {
+		replaced[http.CanonicalHeaderKey(key)] = true
+	}
+	for key := range nc.headers {
+		if replaced[http.CanonicalHeaderKey(key)] {
+			request.Header.Del(key)
+		}
+	}
 	for key, value := range nc.headers {
 		request.Header.Add(key, value)
 	}
@@ -303,73 +486,100 @@ func (nc networkClient) sendRequest(request *http.Request) *errors.ErrorDetails
 	if nc.ctx != nil {
 		request = request.WithContext(nc.ctx)
 	}
+	return request
+}
 
-	res, err := nc.client.Do(request)
+// SendRequest sends the actual HTTP request and handles the response.
+// It executes the request through the middleware chain and decodes the response.
+//
+// Parameters:
+// - request: The HTTP request to send.
+func (nc networkClient) sendRequest(request *http.Request) (*Response, *errors.ErrorDetails) {
+	start := time.Now()
+	res, err := nc.doCoalesced(request)
 
 	if err != nil {
-		//configs.Sugar.Error(constants.SomethingWentWrongDownstream + err.Error())
-		return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
-	} else {
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
-		}(res.Body)
+		return nil, transportException(err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(res.Body)
 
-		bodyBytes, err := io.ReadAll(res.Body)
+	bodyBytes, err := io.ReadAll(res.Body)
+	response := newResponse(request, res, bodyBytes, time.Since(start))
 
-		if err != nil {
-			//configs.Sugar.Error(err.Error())
-			return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, res.StatusCode)
-		}
-		bodyString := string(bodyBytes)
-		var resBody bytes.Buffer
-		err = json.Indent(&resBody, bodyBytes, "", "\t")
-		if err == nil {
-			bodyString = resBody.String()
+	if err != nil {
+		//configs.Sugar.Error(err.Error())
+		return response, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, res.StatusCode)
+	}
+	return response, nc.handleResponse(request, res, bodyBytes)
+}
+
+// HandleResponse decodes the response body based on the status series.
+// Successful bodies are parsed into the response target, error bodies are parsed into ErrorDetails.
+//
+// Parameters:
+// - request: The HTTP request that was sent.
+// - res: The HTTP response received.
+// - bodyBytes: The raw response body.
+func (nc networkClient) handleResponse(request *http.Request, res *http.Response, bodyBytes []byte) *errors.ErrorDetails {
+	bodyString := string(bodyBytes)
+	var resBody bytes.Buffer
+	err := json.Indent(&resBody, bodyBytes, "", "\t")
+	if err == nil {
+		bodyString = resBody.String()
+	}
+	// Build request information string for potential logging
+	_ = "<-- " + strconv.Itoa(res.StatusCode) + " : " + request.Method + " " + request.URL.String()
+	switch enums.HttpStatus(res.StatusCode).SeriesType() {
+	case enums.Successful:
+		if nc.response != nil && !hasNoContent(request, res) {
+			appErr := nc.decodeResponse(res, bodyString, bodyBytes)
+			//configs.Sugar.Infow(uri + " success, Response: \n" + bodyString)
+			return appErr
 		}
-		// Build request information string for potential logging
-		_ = "<-- " + strconv.Itoa(res.StatusCode) + " : " + request.Method + " " + request.URL.String()
-		switch enums.HttpStatus(res.StatusCode).SeriesType() {
-		case enums.Successful:
-			if nc.response != nil {
-				appErr := nc.parser(bodyString, nc.response)
-				//configs.Sugar.Infow(uri + " success, Response: \n" + bodyString)
-				return appErr
-			}
+		return nil
+	case enums.Redirection:
+		if res.StatusCode == http.StatusNotModified {
 			return nil
-		case enums.ClientError:
-			//configs.Sugar.Infow(uri + " failure, Response: \n" + bodyString)
-			return exceptions.GenericException(nc.errorParser(bodyString).Message, bodyString, res.StatusCode)
-		case enums.ServerError:
-			//configs.Sugar.Infow(uri + " failure, Response: \n" + bodyString)
-			return exceptions.GenericException(constants.SomethingWentWrong, bodyString, res.StatusCode)
 		}
+		// Redirects reaching this point were not followed, so there is no body to decode
+		return exceptions.GenericException(constants.RedirectNotFollowed, res.Header.Get(constants.Location), res.StatusCode)
+	case enums.ClientError:
+		//configs.Sugar.Infow(uri + " failure, Response: \n" + bodyString)
+		return exceptions.GenericException(nc.errorParser(bodyString).Message, bodyString, res.StatusCode)
+	case enums.ServerError:
+		//configs.Sugar.Infow(uri + " failure, Response: \n" + bodyString)
+		return exceptions.GenericException(constants.SomethingWentWrong, bodyString, res.StatusCode)
 	}
 	return nil
 }
 
-// SendFormUrlEncoded sends a form URL encoded request to the specified endpoint.
-// This method is called by the send method when the request type is form URL encoded.
+// TransportException converts an error returned while executing a request into ErrorDetails.
 //
 // Parameters:
-// - method: The HTTP method to use.
-// - endpoint: The endpoint to send the request to.
-func (nc networkClient) sendFormUrlEncoded(method enums.HttpMethods, endpoint string) *errors.ErrorDetails {
-	data := url.Values{}
-
-	for k, v := range nc.body.(map[string]string) {
-		data.Set(k, v)
+// - err: The error returned by the middleware chain.
+func transportException(err error) *errors.ErrorDetails {
+	var circuitOpen *errors.CircuitOpenError
+	if stderrors.As(err, &circuitOpen) {
+		return exceptions.CircuitOpenException(circuitOpen.Host)
 	}
-
-	// Encode the form data into a URL-encoded string
-	encodedData := data.Encode()
-
-	// Create a new HTTP request with the encoded data as the body
-	request, err := http.NewRequest(method.String(), nc.host+endpoint, strings.NewReader(encodedData))
-	if err != nil {
-		//configs.Sugar.Error(constants.SomethingWentWrongDownstream + err.Error())
-		return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+	var rateLimited *errors.RateLimitError
+	if stderrors.As(err, &rateLimited) {
+		return exceptions.RateLimitException(rateLimited)
 	}
-
-	return nc.sendRequest(request)
-
+	var oauth2Err *errors.OAuth2Error
+	if stderrors.As(err, &oauth2Err) {
+		return exceptions.OAuth2Exception(oauth2Err)
+	}
+	var signatureErr *errors.SignatureError
+	if stderrors.As(err, &signatureErr) {
+		return exceptions.SignatureException(signatureErr)
+	}
+	var tooManyRedirects *errors.TooManyRedirectsError
+	if stderrors.As(err, &tooManyRedirects) {
+		return exceptions.TooManyRedirectsException(tooManyRedirects)
+	}
+	//configs.Sugar.Error(constants.SomethingWentWrongDownstream + err.Error())
+	return exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
 }
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/xander1235/gorest/constants"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// flightGroup tracks the coalesced requests in flight for a client.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a request in flight whose outcome is shared by every caller with the same key.
+type flight struct {
+	done chan struct{}
+	res  *http.Response
+	body []byte
+	err  error
+}
+
+// WithCoalescing enables request coalescing for every GET and HEAD request made by the client.
+// Concurrent requests with the same method, URL, Authorization header and values of the given headers
+// share a single upstream call, and every caller decodes its own copy of the response.
+//
+// Parameters:
+// - headers: The request headers that, together with the method and URL, identify identical requests.
+func WithCoalescing(headers ...string) Option {
+	return func(nc *networkClient) {
+		nc.coalesce = true
+		nc.coalesceHeaders = headers
+	}
+}
+
+// Coalesce enables request coalescing for the request, sharing a single upstream call with
+// concurrent identical requests made through the same client. Requests are identical when they
+// have the same method, URL, Authorization header and values of the given headers. Only GET and HEAD
+// requests sent with Get, Execute and the generic helpers are coalesced; the context of the first
+// caller governs the shared call, and the other callers stop waiting when their own context is done.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - headers: The request headers that, together with the method and URL, identify identical requests.
+func (nc networkClient) Coalesce(headers ...string) networkClient {
+	nc.coalesce = true
+	nc.coalesceHeaders = headers
+	return nc
+}
+
+// doCoalesced executes the request through the middleware chain, following redirects and sharing the call with
+// identical requests in flight when coalescing is enabled. Coalesced responses are returned
+// with their body already read, so every caller gets its own copy.
+func (nc networkClient) doCoalesced(request *http.Request) (*http.Response, error) {
+	if !nc.coalesce || nc.flights == nil || (request.Method != http.MethodGet && request.Method != http.MethodHead) {
+		return nc.doWithRedirects(request)
+	}
+
+	var key strings.Builder
+	key.WriteString(request.Method + " " + request.URL.String())
+	// Requests made with different credentials never share a response
+	key.WriteString("\n" + constants.Authorization + ": " + strings.Join(request.Header.Values(constants.Authorization), ","))
+	for _, header := range nc.coalesceHeaders {
+		key.WriteString("\n" + http.CanonicalHeaderKey(header) + ": " + strings.Join(request.Header.Values(header), ","))
+	}
+
+	f := nc.flights.do(request.Context(), key.String(), func() (*http.Response, []byte, error) {
+		res, err := nc.doWithRedirects(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+		}(res.Body)
+		body, err := io.ReadAll(res.Body)
+		return res, body, err
+	})
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	res := *f.res
+	res.Header = f.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(f.body))
+	return &res, nil
+}
+
+// do runs fn for the first caller with the key and makes concurrent callers with the same key
+// wait for its outcome, or until their context is done. A panic in fn is reported to the waiting
+// callers as an error and propagated to the first caller.
+//
+// Parameters:
+// - ctx: The context of the caller.
+// - key: The key identifying identical requests.
+// - fn: The function executing the request.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*http.Response, []byte, error)) *flight {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f
+		case <-ctx.Done():
+			return &flight{err: ctx.Err()}
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			f.res, f.body, f.err = nil, nil, fmt.Errorf("coalesced request panicked: %v", r)
+			defer panic(r)
+		}
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.res, f.body, f.err = fn()
+	return f
+}
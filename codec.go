@@ -0,0 +1,132 @@
+package network
+
+import (
+	"bytes"
+	"github.com/xander1235/gorest/codecs"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// WithCodecs sets the registry of codecs used to encode request bodies and decode response bodies
+// of media types other than JSON, multipart and form URL encoded.
+// Clients use codecs.NewDefaultRegistry by default.
+//
+// Parameters:
+// - registry: The codec registry to use.
+func WithCodecs(registry *codecs.Registry) Option {
+	return func(nc *networkClient) {
+		nc.codecs = registry
+	}
+}
+
+// ContentType sets the media type of the request body, which is encoded with the codec registered
+// for it and sent in the Content-Type header.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - mediaType: The media type of the request body, such as "application/xml".
+func (nc networkClient) ContentType(mediaType string) networkClient {
+	nc.requestType = mediaType
+	return nc
+}
+
+// ResponseType sets the media type of the response body, which is sent in the Accept header and
+// decoded into the response target with the codec registered for it, whatever the response Content-Type.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - mediaType: The media type of the response body, such as "application/yaml".
+func (nc networkClient) ResponseType(mediaType string) networkClient {
+	nc.responseType = mediaType
+	return nc
+}
+
+// lookupCodec returns the codec registered for the media type in the client's registry.
+func (nc networkClient) lookupCodec(mediaType string) (codecs.Codec, bool) {
+	if nc.codecs == nil {
+		return nil, false
+	}
+	return nc.codecs.Lookup(mediaType)
+}
+
+// newCodecRequest builds a request to the specified endpoint whose body is encoded with the codec.
+// This method is called by the newRequest method when the request type is registered in the codec registry.
+//
+// Parameters:
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+// - codec: The codec encoding the body.
+func (nc networkClient) newCodecRequest(method enums.HttpMethods, endpoint string, codec codecs.Codec) (*http.Request, *errors.ErrorDetails) {
+	var body []byte
+	if nc.body != nil {
+		var err error
+		body, err = codec.Marshal(nc.body)
+		if err != nil {
+			return nil, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+		}
+	}
+	request, err := http.NewRequest(method.String(), nc.host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, exceptions.GenericException(constants.SomethingWentWrong, err.Error(), 500)
+	}
+	return request, nil
+}
+
+// accept returns the Accept header value derived from the client's decoders: the response type
+// when one is set, or the media types of the codec registry, preferring the first one registered.
+// No value is returned when responses are decoded by a custom Parser.
+func (nc networkClient) accept() string {
+	if nc.responseType != "" {
+		return nc.responseType
+	}
+	if nc.customParser || nc.codecs == nil {
+		return ""
+	}
+	mediaTypes := nc.codecs.MediaTypes()
+	for i := 1; i < len(mediaTypes); i++ {
+		mediaTypes[i] += ";q=0.9"
+	}
+	return strings.Join(mediaTypes, ", ")
+}
+
+// decodeResponse decodes a successful response body into the response target. The body is decoded
+// with the codec of the response type when one is set, with the Parser when it was customized or
+// the response has no Content-Type or a text/plain one, which servers often send with JSON bodies,
+// and with the codec registered for the response Content-Type otherwise.
+// Responses of a media type without a codec fail with an *errors.UnsupportedMediaTypeError.
+//
+// Parameters:
+// - res: The HTTP response.
+// - bodyString: The response body, indented when it is JSON.
+// - bodyBytes: The raw response body.
+func (nc networkClient) decodeResponse(res *http.Response, bodyString string, bodyBytes []byte) *errors.ErrorDetails {
+	contentType := nc.responseType
+	if contentType == "" {
+		contentType = res.Header.Get(constants.ContentType)
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if nc.customParser || nc.codecs == nil || contentType == "" || mediaType == "text/plain" {
+			return nc.parser(bodyString, nc.response)
+		}
+	}
+	codec, ok := nc.lookupCodec(contentType)
+	if !ok {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			mediaType = contentType
+		}
+		var supported []string
+		if nc.codecs != nil {
+			supported = nc.codecs.MediaTypes()
+		}
+		return exceptions.UnsupportedMediaTypeException(&errors.UnsupportedMediaTypeError{MediaType: mediaType, Supported: supported})
+	}
+	if err := codec.Unmarshal(bodyBytes, nc.response); err != nil {
+		return exceptions.GenericException(err.Error(), err, 500)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// codecs package contains the encoders and decoders of request and response bodies.
+package codecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec encodes request bodies and decodes response bodies of a media type.
+type Codec interface {
+	// MediaType returns the media type sent in the Content-Type header of encoded bodies.
+	MediaType() string
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into v.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is the codec of application/json bodies.
+type JSON struct{}
+
+// MediaType returns "application/json".
+func (JSON) MediaType() string {
+	return "application/json"
+}
+
+// Marshal encodes v as JSON.
+func (JSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON data into v.
+func (JSON) Unmarshal(data []byte, v any) error {
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// XML is the codec of application/xml bodies.
+type XML struct{}
+
+// MediaType returns "application/xml".
+func (XML) MediaType() string {
+	return "application/xml"
+}
+
+// Marshal encodes v as XML, preceded by the XML declaration.
+func (XML) Marshal(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Unmarshal decodes the XML data into v.
+func (XML) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// YAML is the codec of application/yaml bodies.
+type YAML struct{}
+
+// MediaType returns "application/yaml".
+func (YAML) MediaType() string {
+	return "application/yaml"
+}
+
+// Marshal encodes v as YAML.
+func (YAML) Marshal(v any) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+// Unmarshal decodes the YAML data into v.
+func (YAML) Unmarshal(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// MessagePack is the codec of application/msgpack bodies.
+type MessagePack struct{}
+
+// MediaType returns "application/msgpack".
+func (MessagePack) MediaType() string {
+	return "application/msgpack"
+}
+
+// Marshal encodes v as MessagePack.
+func (MessagePack) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal decodes the MessagePack data into v.
+func (MessagePack) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
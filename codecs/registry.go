@@ -0,0 +1,89 @@
+package codecs
+
+import (
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Registry maps media types to the codecs encoding and decoding them.
+// It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	codecs     map[string]Codec
+	mediaTypes []string
+}
+
+// NewRegistry creates a registry with the given codecs, each registered under its own media type.
+//
+// Parameters:
+// - codecs: The codecs to register.
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{codecs: map[string]Codec{}}
+	for _, codec := range codecs {
+		r.Register(codec)
+	}
+	return r
+}
+
+// NewDefaultRegistry creates a registry with the built-in JSON, XML, YAML and MessagePack codecs,
+// also registered under their common alternative media types.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(JSON{})
+	r.Register(XML{}, "application/xml", "text/xml")
+	r.Register(YAML{}, "application/yaml", "application/x-yaml", "text/yaml")
+	r.Register(MessagePack{}, "application/msgpack", "application/x-msgpack", "application/vnd.msgpack")
+	return r
+}
+
+// Register registers the codec under the given media types, replacing the codecs already registered
+// for them. When no media type is given, the codec is registered under its own media type.
+//
+// Parameters:
+// - codec: The codec to register.
+// - mediaTypes: The media types the codec encodes and decodes.
+func (r *Registry) Register(codec Codec, mediaTypes ...string) {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{codec.MediaType()}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, mediaType := range mediaTypes {
+		mediaType = strings.ToLower(mediaType)
+		if _, ok := r.codecs[mediaType]; !ok {
+			r.mediaTypes = append(r.mediaTypes, mediaType)
+		}
+		r.codecs[mediaType] = codec
+	}
+}
+
+// Lookup returns the codec registered for the media type of a Content-Type value, ignoring its parameters.
+// Media types with a structured syntax suffix, such as application/problem+json, fall back to the
+// codec registered for the suffix, such as application/json.
+//
+// Parameters:
+// - contentType: The Content-Type value to look up.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if codec, ok := r.codecs[mediaType]; ok {
+		return codec, true
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		codec, ok := r.codecs["application/"+mediaType[i+1:]]
+		return codec, ok
+	}
+	return nil, false
+}
+
+// MediaTypes returns the registered media types in registration order.
+func (r *Registry) MediaTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.mediaTypes...)
+}
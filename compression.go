@@ -0,0 +1,215 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptedEncodings is the Accept-Encoding value sent when response decompression is enabled.
+const acceptedEncodings = "gzip, deflate, br, zstd"
+
+// Compression configures the compression of request bodies.
+type Compression struct {
+	// Encoding is the content coding used to compress the body.
+	Encoding enums.ContentEncoding
+	// MinSize is the size in bytes from which bodies are compressed. Smaller bodies are sent as is.
+	MinSize int64
+}
+
+// WithRequestCompression compresses the JSON and form URL encoded bodies of every request made
+// by the client with the given content coding once they reach minSize bytes, setting Content-Encoding.
+//
+// Parameters:
+// - encoding: The content coding used to compress bodies.
+// - minSize: The size in bytes from which bodies are compressed.
+func WithRequestCompression(encoding enums.ContentEncoding, minSize int64) Option {
+	return func(nc *networkClient) {
+		nc.compression = &Compression{Encoding: encoding, MinSize: minSize}
+	}
+}
+
+// WithResponseDecompression enables or disables response decompression, which is enabled by default.
+// When enabled and the request has no Accept-Encoding header, the client negotiates gzip, deflate,
+// br and zstd, and decodes the response body before it is parsed. When disabled, the
+// transparent gzip handling of the underlying http.Client applies.
+//
+// Parameters:
+// - enabled: Whether responses are decompressed by the client.
+func WithResponseDecompression(enabled bool) Option {
+	return func(nc *networkClient) {
+		nc.disableDecompression = !enabled
+	}
+}
+
+// Compress compresses the JSON or form URL encoded body of the request with the given content coding
+// once it reaches minSize bytes, overriding the client's request compression.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - encoding: The content coding used to compress the body.
+// - minSize: The size in bytes from which the body is compressed.
+func (nc networkClient) Compress(encoding enums.ContentEncoding, minSize int64) networkClient {
+	nc.compression = &Compression{Encoding: encoding, MinSize: minSize}
+	return nc
+}
+
+// compressBody compresses the replayable body of the request according to the compression settings.
+//
+// Parameters:
+// - request: The HTTP request whose body is compressed.
+func (nc networkClient) compressBody(request *http.Request) error {
+	if nc.compression == nil || request.GetBody == nil || request.ContentLength < max(nc.compression.MinSize, 1) {
+		return nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return err
+	}
+	var compressed bytes.Buffer
+	writer, err := newCompressor(&compressed, nc.compression.Encoding)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(writer, body); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	compressedBytes := compressed.Bytes()
+	request.Body = io.NopCloser(bytes.NewReader(compressedBytes))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressedBytes)), nil
+	}
+	request.ContentLength = int64(len(compressedBytes))
+	request.Header.Set(constants.ContentEncoding, nc.compression.Encoding.String())
+	return nil
+}
+
+// newCompressor creates a writer compressing into w with the given content coding.
+func newCompressor(w io.Writer, encoding enums.ContentEncoding) (io.WriteCloser, error) {
+	switch encoding {
+	case enums.Gzip:
+		return gzip.NewWriter(w), nil
+	case enums.Deflate:
+		return zlib.NewWriter(w), nil
+	case enums.Zstd:
+		return zstd.NewWriter(w)
+	case enums.Brotli:
+		return brotli.NewWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+// doDecompressed executes a single attempt of the request with the underlying HTTP client,
+// negotiating and decoding the response content coding when decompression is enabled.
+func (nc networkClient) doDecompressed(request *http.Request) (*http.Response, error) {
+	if nc.disableDecompression || request.Header.Get(constants.AcceptEncoding) != "" {
+		return nc.httpClient().Do(request)
+	}
+	request.Header.Set(constants.AcceptEncoding, acceptedEncodings)
+	res, err := nc.httpClient().Do(request)
+	if err != nil || !hasBody(request, res) {
+		return res, err
+	}
+
+	encodings := strings.Split(res.Header.Get(constants.ContentEncoding), ",")
+	if len(encodings) == 1 && encodings[0] == "" {
+		return res, nil
+	}
+	// Bodies without content are passed through, as decoders fail on them
+	buffered := bufio.NewReader(res.Body)
+	if _, peekErr := buffered.Peek(1); peekErr == io.EOF {
+		return res, nil
+	}
+	body := io.NopCloser(buffered)
+	// Codings are listed in the order they were applied, so they are decoded in reverse
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decoded, decodeErr := newDecompressor(body, enums.ContentEncoding(encoding))
+		if decodeErr != nil {
+			_ = res.Body.Close() // Intentionally ignoring error as the response is abandoned
+			return nil, decodeErr
+		}
+		body = decoded
+	}
+	if body != res.Body {
+		res.Body = readCloser{Reader: body, closers: []io.Closer{body, res.Body}}
+		res.Header.Del(constants.ContentEncoding)
+		res.Header.Del(constants.ContentLength)
+		res.ContentLength = -1
+		res.Uncompressed = true
+	}
+	return res, nil
+}
+
+// hasBody checks if the response may have content to decode, which is not the case for HEAD requests,
+// 1xx, 204 and 304 responses and responses with an empty body.
+func hasBody(request *http.Request, res *http.Response) bool {
+	switch {
+	case request.Method == http.MethodHead, res.ContentLength == 0:
+		return false
+	case res.StatusCode < 200, res.StatusCode == http.StatusNoContent, res.StatusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// newDecompressor creates a reader decoding r with the given content coding.
+// Deflate bodies are accepted both as zlib streams and as raw deflate data, which some servers send.
+func newDecompressor(r io.ReadCloser, encoding enums.ContentEncoding) (io.ReadCloser, error) {
+	switch encoding {
+	case enums.Gzip, "x-gzip":
+		return gzip.NewReader(r)
+	case enums.Deflate:
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case enums.Zstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case enums.Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+// readCloser reads from a decoding reader and closes it together with the original body.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes the decoding reader and the original body.
+func (r readCloser) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
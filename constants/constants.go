@@ -9,8 +9,77 @@ const ContentType = "Content-Type"
 // XRequestId represents the "X-Request-ID" HTTP header used to uniquely identify requests.
 const XRequestId = "X-Request-ID"
 
+// Accept represents the "Accept" HTTP header used to indicate the media types the client can understand.
+const Accept = "Accept"
+
+// CacheControl represents the "Cache-Control" HTTP header used to specify caching directives.
+const CacheControl = "Cache-Control"
+
+// LastEventId represents the "Last-Event-ID" HTTP header used to resume a Server-Sent Events stream.
+const LastEventId = "Last-Event-ID"
+
+// EventStream is the media type of Server-Sent Events streams.
+const EventStream = "text/event-stream"
+
+// AcceptEncoding represents the "Accept-Encoding" HTTP header used to indicate the content codings the client can decode.
+const AcceptEncoding = "Accept-Encoding"
+
+// ContentEncoding represents the "Content-Encoding" HTTP header used to indicate the content codings applied to the body.
+const ContentEncoding = "Content-Encoding"
+
+// ContentLength represents the "Content-Length" HTTP header used to indicate the size of the body.
+const ContentLength = "Content-Length"
+
+// Authorization represents the "Authorization" HTTP header used to send credentials.
+const Authorization = "Authorization"
+
+// ProxyAuthorization represents the "Proxy-Authorization" HTTP header used to send credentials to a proxy.
+const ProxyAuthorization = "Proxy-Authorization"
+
+// WwwAuthenticate represents the "WWW-Authenticate" HTTP header used to send authentication challenges.
+const WwwAuthenticate = "WWW-Authenticate"
+
+// ProxyAuthenticate represents the "Proxy-Authenticate" HTTP header used to send the authentication challenges of a proxy.
+const ProxyAuthenticate = "Proxy-Authenticate"
+
+// Cookie represents the "Cookie" HTTP header used to send cookies.
+const Cookie = "Cookie"
+
+// Cookie2 represents the obsolete "Cookie2" HTTP header used to send cookies (RFC 2965).
+const Cookie2 = "Cookie2"
+
+// Location represents the "Location" HTTP header used to indicate the target of a redirect.
+const Location = "Location"
+
+// RetryAfter represents the "Retry-After" HTTP header used to indicate how long to wait before making a follow-up request.
+const RetryAfter = "Retry-After"
+
 // InvalidRequestType is an error message indicating that the request content type is invalid.
 const InvalidRequestType = "Invalid request content type"
 
 // SomethingWentWrong is a general error message indicating that something went wrong during processing.
 const SomethingWentWrong = "Something went wrong"
+
+// CircuitOpen is an error message indicating that a request was rejected by an open circuit breaker.
+const CircuitOpen = "Circuit breaker is open"
+
+// RateLimited is an error message indicating that a request was rejected by the client-side rate limiter.
+const RateLimited = "Rate limit exceeded"
+
+// InvalidResponseType is an error message indicating that the response content type is not supported.
+const InvalidResponseType = "Invalid response content type"
+
+// TooManyRedirects is an error message indicating that a request was redirected more often than allowed.
+const TooManyRedirects = "Too many redirects"
+
+// RedirectNotFollowed is an error message indicating that a redirect response was not followed.
+const RedirectNotFollowed = "Redirect not followed"
+
+// AuthenticationFailed is an error message indicating that the credentials of a request could not be obtained.
+const AuthenticationFailed = "Authentication failed"
+
+// InvalidSignature is an error message indicating that the signature of a response could not be verified.
+const InvalidSignature = "Invalid response signature"
+
+// StreamClosed is an error message indicating that a stream ended and could not be reconnected.
+const StreamClosed = "Stream closed"
@@ -0,0 +1,24 @@
+package enums
+
+// ContentEncoding represents the content codings used to compress HTTP bodies.
+// It is defined as a string type for better type safety.
+type ContentEncoding string
+
+const (
+	// Gzip represents the gzip content coding.
+	Gzip ContentEncoding = "gzip"
+
+	// Deflate represents the deflate content coding, a zlib stream.
+	Deflate ContentEncoding = "deflate"
+
+	// Zstd represents the Zstandard content coding.
+	Zstd ContentEncoding = "zstd"
+
+	// Brotli represents the Brotli content coding.
+	Brotli ContentEncoding = "br"
+)
+
+// String returns the string representation of the content coding.
+func (encoding ContentEncoding) String() string {
+	return string(encoding)
+}
@@ -0,0 +1,21 @@
+package enums
+
+// GrantType represents the OAuth2 grant used to obtain access tokens.
+// It is defined as a string type for better type safety.
+type GrantType string
+
+const (
+	// ClientCredentials represents the client credentials grant (RFC 6749 section 4.4).
+	ClientCredentials GrantType = "client_credentials"
+
+	// RefreshToken represents the refresh token grant (RFC 6749 section 6).
+	RefreshToken GrantType = "refresh_token"
+
+	// Password represents the resource owner password credentials grant (RFC 6749 section 4.3).
+	Password GrantType = "password"
+)
+
+// String returns the string representation of the grant type.
+func (grant GrantType) String() string {
+	return string(grant)
+}
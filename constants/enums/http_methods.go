@@ -19,9 +19,26 @@ const (
 
 	// GET represents the HTTP GET method.
 	GET HttpMethods = "GET"
+
+	// HEAD represents the HTTP HEAD method.
+	HEAD HttpMethods = "HEAD"
+
+	// OPTIONS represents the HTTP OPTIONS method.
+	OPTIONS HttpMethods = "OPTIONS"
 )
 
 // String returns the string representation of the HTTP method.
 func (httpMethod HttpMethods) String() string {
 	return string(httpMethod)
 }
+
+// IsIdempotent checks if the HTTP method is idempotent as defined by RFC 9110.
+// Requests with idempotent methods can safely be repeated.
+func (httpMethod HttpMethods) IsIdempotent() bool {
+	switch httpMethod {
+	case GET, HEAD, OPTIONS, PUT, DELETE, "TRACE":
+		return true
+	default:
+		return false
+	}
+}
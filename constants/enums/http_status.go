@@ -14,6 +14,17 @@ const (
 	ServerError   HttpStatus = 5
 )
 
+const (
+	// TooManyRequests represents the 429 status code.
+	TooManyRequests HttpStatus = 429
+	// BadGateway represents the 502 status code.
+	BadGateway HttpStatus = 502
+	// ServiceUnavailable represents the 503 status code.
+	ServiceUnavailable HttpStatus = 503
+	// GatewayTimeout represents the 504 status code.
+	GatewayTimeout HttpStatus = 504
+)
+
 // Is1XXSeries checks if the status code is in the 1xx series.
 func (status HttpStatus) Is1XXSeries() bool {
 	return status/100 == Informational
@@ -0,0 +1,21 @@
+package enums
+
+// JwtAlgorithm represents the JSON Web Signature algorithms of RFC 7518 and RFC 8037 used to sign JWTs.
+// It is defined as a string type for better type safety.
+type JwtAlgorithm string
+
+const (
+	// Rs256 represents RSASSA-PKCS1-v1_5 using SHA-256.
+	Rs256 JwtAlgorithm = "RS256"
+
+	// Es256 represents ECDSA using curve P-256 and SHA-256.
+	Es256 JwtAlgorithm = "ES256"
+
+	// EdDsa represents EdDSA using curve edwards25519.
+	EdDsa JwtAlgorithm = "EdDSA"
+)
+
+// String returns the string representation of the JWT algorithm.
+func (algorithm JwtAlgorithm) String() string {
+	return string(algorithm)
+}
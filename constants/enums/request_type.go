@@ -10,11 +10,14 @@ const (
 	Json
 	Multipart
 	FormUrlEncoded
+	Xml
+	Yaml
+	MsgPack
 )
 
 // Values returns the values of the RequestType.
 func (s RequestType) Values() []string {
-	return []string{"application/json", "multipart/form-data", "application/x-www-form-urlencoded"}
+	return []string{"application/json", "multipart/form-data", "application/x-www-form-urlencoded", "application/xml", "application/yaml", "application/msgpack"}
 }
 
 // ToString returns the string representation of the RequestType.
@@ -0,0 +1,24 @@
+package enums
+
+// SignatureAlgorithm represents the HTTP message signature algorithms of RFC 9421.
+// It is defined as a string type for better type safety.
+type SignatureAlgorithm string
+
+const (
+	// HmacSha256 represents HMAC using SHA-256, keyed with a shared secret.
+	HmacSha256 SignatureAlgorithm = "hmac-sha256"
+
+	// Ed25519 represents EdDSA using curve edwards25519.
+	Ed25519 SignatureAlgorithm = "ed25519"
+
+	// EcdsaP256Sha256 represents ECDSA using curve P-256 and SHA-256.
+	EcdsaP256Sha256 SignatureAlgorithm = "ecdsa-p256-sha256"
+
+	// RsaPssSha512 represents RSASSA-PSS using SHA-512.
+	RsaPssSha512 SignatureAlgorithm = "rsa-pss-sha512"
+)
+
+// String returns the string representation of the signature algorithm.
+func (algorithm SignatureAlgorithm) String() string {
+	return string(algorithm)
+}
@@ -0,0 +1,41 @@
+package enums
+
+// StreamFormat represents the format of a streamed sequence of JSON values.
+// It is defined as an int type for better type safety.
+type StreamFormat int
+
+const (
+	// StreamFormatType represents the format of a streamed sequence of JSON values.
+	StreamFormatType StreamFormat = iota
+	// NDJson represents newline-delimited JSON values.
+	NDJson
+	// JsonSeq represents RFC 7464 JSON text sequences.
+	JsonSeq
+	// JsonArray represents the elements of a single top-level JSON array.
+	JsonArray
+)
+
+// Values returns the values of the StreamFormat.
+func (s StreamFormat) Values() []string {
+	return []string{"application/x-ndjson", "application/json-seq", "application/json"}
+}
+
+// ToString returns the string representation of the StreamFormat.
+func (s StreamFormat) ToString() string {
+	return s.Values()[s-1]
+}
+
+// ValueOf returns the StreamFormat value for the given media type.
+// Common aliases of newline-delimited JSON are recognized as NDJson.
+func (s StreamFormat) ValueOf(value string) StreamFormat {
+	switch value {
+	case "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return NDJson
+	}
+	for i, g := range s.Values() {
+		if g == value {
+			return StreamFormat(i + 1)
+		}
+	}
+	return StreamFormat(-1)
+}
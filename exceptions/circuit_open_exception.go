@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// CircuitOpenException creates a new ErrorDetails instance for a request rejected by an open circuit breaker.
+// The Error field holds an *errors.CircuitOpenError for the host.
+func CircuitOpenException(host string) *errors.ErrorDetails {
+	return GenericException(constants.CircuitOpen, &errors.CircuitOpenError{Host: host}, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,12 @@
+package errors
+
+// CircuitOpenError is the error reported when a request is short-circuited
+// because the circuit breaker for its host is open.
+type CircuitOpenError struct {
+	Host string `json:"host"`
+}
+
+// Error returns the error message for the short-circuited host.
+func (e *CircuitOpenError) Error() string {
+	return "circuit breaker is open for host " + e.Host
+}
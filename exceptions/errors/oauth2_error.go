@@ -0,0 +1,25 @@
+package errors
+
+import "strconv"
+
+// OAuth2Error is the error reported when an OAuth2 token request fails (RFC 6749 section 5.2).
+type OAuth2Error struct {
+	// StatusCode is the HTTP status code of the token response, or 0 if no response was received.
+	StatusCode int `json:"status_code"`
+	// Code is the OAuth2 error code, e.g. "invalid_client".
+	Code string `json:"error"`
+	// Description is the human-readable description of the error.
+	Description string `json:"error_description"`
+}
+
+// Error returns the error message for the failed token request.
+func (e *OAuth2Error) Error() string {
+	message := "oauth2 token request failed with status " + strconv.Itoa(e.StatusCode)
+	if e.Code != "" {
+		message += ": " + e.Code
+	}
+	if e.Description != "" {
+		message += " (" + e.Description + ")"
+	}
+	return message
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimitError is the error reported when a request is rejected by the client-side rate limiter.
+type RateLimitError struct {
+	// Key identifies the exhausted limit, e.g. "client", a host or an endpoint pattern.
+	Key string `json:"key"`
+	// RetryAfter is the time until a token becomes available for the limit.
+	// It is the maximum duration when the limit adds no tokens.
+	RetryAfter time.Duration `json:"retry_after"`
+}
+
+// Error returns the error message for the exhausted limit.
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter == math.MaxInt64 {
+		return "rate limit exhausted for " + e.Key
+	}
+	return "rate limit exceeded for " + e.Key + ", retry after " + e.RetryAfter.String()
+}
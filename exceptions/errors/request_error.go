@@ -0,0 +1,31 @@
+package errors
+
+import "strconv"
+
+// RequestError wraps ErrorDetails so it can be returned as an error.
+// The wrapped cause, when ErrorDetails.Error holds an error, is available through errors.Unwrap.
+type RequestError struct {
+	*ErrorDetails
+}
+
+// Error returns the error message together with the response code.
+func (e *RequestError) Error() string {
+	return e.Message + " (response code " + strconv.Itoa(e.ResponseCode) + ")"
+}
+
+// Unwrap returns the error held by ErrorDetails.Error, if any.
+func (e *RequestError) Unwrap() error {
+	if err, ok := e.ErrorDetails.Error.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// AsError returns the ErrorDetails as an error, or nil if there are no details.
+// Use it instead of converting a nil *ErrorDetails, which yields a non-nil error.
+func (e *ErrorDetails) AsError() error {
+	if e == nil {
+		return nil
+	}
+	return &RequestError{ErrorDetails: e}
+}
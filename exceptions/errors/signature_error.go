@@ -0,0 +1,12 @@
+package errors
+
+// SignatureError is the error reported when the signature of a response is missing or invalid.
+type SignatureError struct {
+	// Reason describes why the signature was rejected.
+	Reason string `json:"reason"`
+}
+
+// Error returns the error message for the rejected signature.
+func (e *SignatureError) Error() string {
+	return "invalid response signature: " + e.Reason
+}
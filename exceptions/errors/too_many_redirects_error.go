@@ -0,0 +1,16 @@
+package errors
+
+import "strconv"
+
+// TooManyRedirectsError is the error reported when a request is redirected more often than the redirect policy allows.
+type TooManyRedirectsError struct {
+	// URL is the URL of the original request.
+	URL string `json:"url"`
+	// MaxRedirects is the maximum number of redirects allowed by the policy.
+	MaxRedirects int `json:"max_redirects"`
+}
+
+// Error returns the error message for the redirected request.
+func (e *TooManyRedirectsError) Error() string {
+	return "stopped after " + strconv.Itoa(e.MaxRedirects) + " redirects for " + e.URL
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "strings"
+
+// UnsupportedMediaTypeError is the error reported when a response body has a media type
+// that none of the client's decoders supports.
+type UnsupportedMediaTypeError struct {
+	// MediaType is the media type of the response, taken from its Content-Type header.
+	MediaType string `json:"media_type"`
+	// Supported lists the media types the client can decode.
+	Supported []string `json:"supported"`
+}
+
+// Error returns the error message for the unsupported media type.
+func (e *UnsupportedMediaTypeError) Error() string {
+	return "unsupported response media type " + e.MediaType + ", expected one of " + strings.Join(e.Supported, ", ")
+}
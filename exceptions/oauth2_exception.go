@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// OAuth2Exception creates a new ErrorDetails instance for a request whose access token could not be obtained.
+// The Error field holds the given *errors.OAuth2Error.
+func OAuth2Exception(err *errors.OAuth2Error) *errors.ErrorDetails {
+	return GenericException(constants.AuthenticationFailed, err, http.StatusUnauthorized)
+}
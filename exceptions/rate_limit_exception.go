@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// RateLimitException creates a new ErrorDetails instance for a request rejected by the client-side rate limiter.
+// The Error field holds the given *errors.RateLimitError.
+func RateLimitException(err *errors.RateLimitError) *errors.ErrorDetails {
+	return GenericException(constants.RateLimited, err, http.StatusTooManyRequests)
+}
@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// SignatureException creates a new ErrorDetails instance for a response whose signature could not be verified.
+// The Error field holds the given *errors.SignatureError.
+func SignatureException(err *errors.SignatureError) *errors.ErrorDetails {
+	return GenericException(constants.InvalidSignature, err, http.StatusBadGateway)
+}
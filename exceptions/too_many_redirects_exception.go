@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// TooManyRedirectsException creates a new ErrorDetails instance for a request redirected more often than allowed.
+// The Error field holds the given *errors.TooManyRedirectsError.
+func TooManyRedirectsException(err *errors.TooManyRedirectsError) *errors.ErrorDetails {
+	return GenericException(constants.TooManyRedirects, err, http.StatusLoopDetected)
+}
@@ -0,0 +1,13 @@
+package exceptions
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// UnsupportedMediaTypeException creates a new ErrorDetails instance for a response whose media type cannot be decoded.
+// The Error field holds the given *errors.UnsupportedMediaTypeError.
+func UnsupportedMediaTypeException(err *errors.UnsupportedMediaTypeError) *errors.ErrorDetails {
+	return GenericException(constants.InvalidResponseType, err, http.StatusUnsupportedMediaType)
+}
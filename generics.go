@@ -0,0 +1,118 @@
+package network
+
+import (
+	"context"
+	"github.com/xander1235/gorest/constants/enums"
+)
+
+// Client is a client the generic helpers send requests with: a client created by New, NetworkClient,
+// or the value returned by any of their chainable methods, e.g. client.Headers(headers).
+type Client interface {
+	// base returns the configuration of the client.
+	base() networkClient
+}
+
+// base returns the configuration of the client.
+func (nc networkClient) base() networkClient {
+	return nc
+}
+
+// resolveClient returns the configuration of the client, or of NetworkClient when the client is nil.
+//
+// Parameters:
+// - client: The client to resolve.
+func resolveClient(client Client) networkClient {
+	if nc, ok := client.(*networkClient); client == nil || (ok && nc == nil) {
+		return *NetworkClient
+	}
+	return client.base()
+}
+
+// Get sends a GET request with the client and decodes the response body into a value of type T.
+// A nil client uses NetworkClient.
+//
+// Example:
+// user, res, err := Get[User](ctx, client, "/users/1")
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - endpoint: The endpoint to send the request to.
+func Get[T any](ctx context.Context, client Client, endpoint string) (T, *Response, error) {
+	return Send[T](ctx, client, enums.GET, endpoint, nil)
+}
+
+// Delete sends a DELETE request with the client and decodes the response body into a value of type T.
+// A nil client uses NetworkClient.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - endpoint: The endpoint to send the request to.
+func Delete[T any](ctx context.Context, client Client, endpoint string) (T, *Response, error) {
+	return Send[T](ctx, client, enums.DELETE, endpoint, nil)
+}
+
+// Post sends a POST request with the body and decodes the response body into a value of type T.
+// A nil client uses NetworkClient.
+//
+// Example:
+// created, res, err := Post[User](ctx, client, "/users", newUser)
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+func Post[T any](ctx context.Context, client Client, endpoint string, body any) (T, *Response, error) {
+	return Send[T](ctx, client, enums.POST, endpoint, body)
+}
+
+// Put sends a PUT request with the body and decodes the response body into a value of type T.
+// A nil client uses NetworkClient.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+func Put[T any](ctx context.Context, client Client, endpoint string, body any) (T, *Response, error) {
+	return Send[T](ctx, client, enums.PUT, endpoint, body)
+}
+
+// Patch sends a PATCH request with the body and decodes the response body into a value of type T.
+// A nil client uses NetworkClient.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+func Patch[T any](ctx context.Context, client Client, endpoint string, body any) (T, *Response, error) {
+	return Send[T](ctx, client, enums.PATCH, endpoint, body)
+}
+
+// Send sends a request with the given method and decodes the response body into a value of type T.
+// A nil body keeps the body configured on the client. A nil client uses NetworkClient,
+// and a nil context keeps the context of the client.
+// The error, if any, is an *errors.RequestError wrapping the ErrorDetails.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+func Send[T any](ctx context.Context, client Client, method enums.HttpMethods, endpoint string, body any) (T, *Response, error) {
+	var out T
+	nc := resolveClient(client)
+	if ctx != nil {
+		nc = nc.WithContext(ctx)
+	}
+	nc = nc.Response(&out)
+	if body != nil {
+		nc = nc.Body(body)
+	}
+	res, appErr := nc.Execute(method, endpoint)
+	return out, res, appErr.AsError()
+}
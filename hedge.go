@@ -0,0 +1,163 @@
+package network
+
+import (
+	"context"
+	"github.com/xander1235/gorest/constants/enums"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HedgePolicy configures request hedging: when an attempt has not responded within Delay,
+// an identical attempt is sent, and the first successful response wins while the others are cancelled.
+// Hedging only applies to idempotent methods with replayable bodies.
+type HedgePolicy struct {
+	// Delay is the time to wait for a response before sending the next attempt.
+	Delay time.Duration
+	// MaxAttempts is the total number of attempts, including the first one. Defaults to 2.
+	MaxAttempts int
+}
+
+// hedgeAttemptKey is the context key holding the 1-based number of a hedged attempt.
+type hedgeAttemptKey struct{}
+
+// hedgeResult is the outcome of a hedged attempt.
+type hedgeResult struct {
+	res     *http.Response
+	err     error
+	attempt int
+}
+
+// Hedge enables request hedging for the request.
+// The attempt that won is reported in Response.HedgeAttempt.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - policy: The hedging policy to use.
+func (nc networkClient) Hedge(policy *HedgePolicy) networkClient {
+	nc.hedgePolicy = policy
+	return nc
+}
+
+// doWithHedging executes the request, sending hedged attempts according to the hedging policy.
+// An attempt succeeds when it returns a response with a status below 500. When no attempt
+// succeeds, the outcome of the last attempt to complete is returned.
+func (nc networkClient) doWithHedging(request *http.Request) (*http.Response, error) {
+	policy := nc.hedgePolicy
+	replayable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	if policy == nil || !enums.HttpMethods(request.Method).IsIdempotent() || !replayable {
+		return nc.doWithRateLimit(request)
+	}
+	attempts := policy.MaxAttempts
+	if attempts < 2 {
+		attempts = 2
+	}
+
+	parent := request.Context()
+	results := make(chan hedgeResult, attempts)
+	cancels := make([]context.CancelFunc, 0, attempts)
+	launch := func() {
+		attempt := len(cancels) + 1
+		ctx, cancel := context.WithCancel(context.WithValue(parent, hedgeAttemptKey{}, attempt))
+		cancels = append(cancels, cancel)
+		attemptRequest := request.Clone(ctx)
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				results <- hedgeResult{err: err, attempt: attempt}
+				return
+			}
+			attemptRequest.Body = body
+		}
+		go func() {
+			res, err := nc.doWithRateLimit(attemptRequest)
+			results <- hedgeResult{res: res, err: err, attempt: attempt}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(policy.Delay)
+	defer timer.Stop()
+	var last hedgeResult
+	for pending := 1; pending > 0; {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil && result.res.StatusCode < 500 {
+				nc.cancelLosers(cancels, result.attempt, results, pending)
+				result.res.Body = &cancelOnClose{ReadCloser: result.res.Body, cancel: cancels[result.attempt-1]}
+				return result.res, nil
+			}
+			if last.res != nil {
+				discardResponse(last.res)
+			}
+			if last.attempt > 0 {
+				cancels[last.attempt-1]()
+			}
+			last = result
+			if len(cancels) < attempts && parent.Err() == nil {
+				// Do not wait for the delay once an attempt has failed
+				launch()
+				pending++
+			}
+		case <-timer.C:
+			// No more attempts are sent once the caller gave up
+			if len(cancels) < attempts && parent.Err() == nil {
+				launch()
+				pending++
+				timer.Reset(policy.Delay)
+			}
+		}
+	}
+	if last.res != nil {
+		last.res.Body = &cancelOnClose{ReadCloser: last.res.Body, cancel: cancels[last.attempt-1]}
+	} else {
+		cancels[last.attempt-1]()
+	}
+	return last.res, last.err
+}
+
+// cancelLosers cancels every attempt but the winner, and releases the responses of the
+// attempts still pending once they complete.
+func (nc networkClient) cancelLosers(cancels []context.CancelFunc, winner int, results <-chan hedgeResult, pending int) {
+	for i, cancel := range cancels {
+		if i != winner-1 {
+			cancel()
+		}
+	}
+	go func() {
+		for ; pending > 0; pending-- {
+			if result := <-results; result.res != nil {
+				discardResponse(result.res)
+			}
+		}
+	}()
+}
+
+// hedgeAttempt returns the number of the hedged attempt that produced the response, or 0 if it was not hedged.
+func hedgeAttempt(res *http.Response) int {
+	if res.Request == nil {
+		return 0
+	}
+	attempt, _ := res.Request.Context().Value(hedgeAttemptKey{}).(int)
+	return attempt
+}
+
+// discardResponse reads and closes the body of a response that is not returned.
+func discardResponse(res *http.Response) {
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close() // Intentionally ignoring error as the response is discarded
+}
+
+// cancelOnClose cancels the context of a hedged attempt when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the attempt.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,62 @@
+package network
+
+import (
+	"github.com/xander1235/gorest/cache"
+	"github.com/xander1235/gorest/types"
+	"net/http"
+)
+
+// WithMiddleware registers middleware applied to every request made by the client.
+// Middleware runs in the order it is registered, with client middleware running
+// before middleware added per request with Use. Retries, rate limiting and the
+// circuit breaker run inside the chain, so every middleware sees each request once.
+//
+// Parameters:
+// - middleware: The middleware to register.
+func WithMiddleware(middleware ...types.Middleware) Option {
+	return func(nc *networkClient) {
+		nc.middleware = append(nc.middleware, middleware...)
+	}
+}
+
+// WithCache registers the HTTP cache as middleware of the client, so responses are served
+// from the cache before retries, rate limiting and the circuit breaker are involved.
+// The position of the cache in the chain follows the registration order of the options.
+//
+// Parameters:
+// - responseCache: The cache to use.
+func WithCache(responseCache *cache.Cache) Option {
+	return WithMiddleware(responseCache.Middleware())
+}
+
+// Use adds middleware for the request, running after the client's middleware.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - middleware: The middleware to add.
+func (nc networkClient) Use(middleware ...types.Middleware) networkClient {
+	chain := make([]types.Middleware, 0, len(nc.middleware)+len(middleware))
+	chain = append(chain, nc.middleware...)
+	nc.middleware = append(chain, middleware...)
+	return nc
+}
+
+// do executes the request through the middleware chain.
+func (nc networkClient) do(request *http.Request) (*http.Response, error) {
+	handler := types.Handler(nc.doWithRetry)
+	for i := len(nc.middleware) - 1; i >= 0; i-- {
+		handler = nc.middleware[i](handler)
+	}
+	return handler(request)
+}
+
+// closeRequestBody closes the body of a request that is rejected without being sent,
+// as the transport would have done, so streamed bodies release their resources.
+//
+// Parameters:
+// - request: The rejected HTTP request.
+func closeRequestBody(request *http.Request) {
+	if request.Body != nil {
+		_ = request.Body.Close() // Intentionally ignoring error as the request is abandoned
+	}
+}
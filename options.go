@@ -0,0 +1,80 @@
+package network
+
+import (
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+)
+
+// Option configures a networkClient created by New.
+type Option func(*networkClient)
+
+// WithHTTPClient sets the underlying HTTP client used to execute requests.
+// This allows for custom transports, timeouts and APM wrappers.
+//
+// Parameters:
+// - client: The HTTP client to use.
+func WithHTTPClient(client *http.Client) Option {
+	return func(nc *networkClient) {
+		if client != nil {
+			nc.client = client
+		}
+	}
+}
+
+// WithHost sets the default base URL for requests made by the client.
+// It can still be overridden per request with the Host method.
+//
+// Parameters:
+// - host: The base URL for the requests.
+func WithHost(host string) Option {
+	return func(nc *networkClient) {
+		nc.host = host
+	}
+}
+
+// WithDefaultHeaders sets headers that are sent with every request made by the client.
+// Headers set per request with the Headers method take precedence over these.
+//
+// Parameters:
+// - headers: A map of header key-value pairs.
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(nc *networkClient) {
+		nc.defaultHeaders = make(map[string]string, len(headers))
+		for key, value := range headers {
+			nc.defaultHeaders[key] = value
+		}
+	}
+}
+
+// WithParser sets the default function used to parse successful responses.
+// A custom parser decodes every successful response, whatever its Content-Type.
+//
+// Parameters:
+// - parser: The function to parse responses.
+func WithParser(parser func(string, any) *errors.ErrorDetails) Option {
+	return func(nc *networkClient) {
+		nc.parser = parser
+		nc.customParser = true
+	}
+}
+
+// WithErrorParser sets the default function used to parse error responses.
+//
+// Parameters:
+// - parser: The function to parse errors.
+func WithErrorParser(parser func(string) *errors.ErrorDetails) Option {
+	return func(nc *networkClient) {
+		nc.errorParser = parser
+	}
+}
+
+// WithRequestType sets the default type of request (e.g., JSON, form URL encoded).
+//
+// Parameters:
+// - requestType: The type of request to set.
+func WithRequestType(requestType enums.RequestType) Option {
+	return func(nc *networkClient) {
+		nc.requestType = requestType.ToString()
+	}
+}
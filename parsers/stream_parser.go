@@ -0,0 +1,97 @@
+// parsers package contains the parsing logic.
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/xander1235/gorest/constants/enums"
+	"io"
+)
+
+// recordSeparator is the byte starting every JSON text of an RFC 7464 sequence.
+const recordSeparator = 0x1E
+
+// StreamDecoder decodes the values of a streamed JSON body one at a time,
+// without reading the whole body into memory.
+type StreamDecoder struct {
+	format  enums.StreamFormat
+	reader  *bufio.Reader
+	decoder *json.Decoder
+	started bool
+}
+
+// NewStreamDecoder creates a decoder for the given stream format.
+//
+// Parameters:
+// - body: The streamed body.
+// - format: The format of the body.
+func NewStreamDecoder(body io.Reader, format enums.StreamFormat) *StreamDecoder {
+	d := &StreamDecoder{format: format}
+	if format == enums.JsonSeq {
+		d.reader = bufio.NewReader(body)
+	} else {
+		d.decoder = json.NewDecoder(body)
+	}
+	return d
+}
+
+// Decode decodes the next value of the stream into v.
+// It returns io.EOF when there are no more values.
+//
+// Parameters:
+// - v: A pointer to the value to decode into.
+func (d *StreamDecoder) Decode(v any) error {
+	switch d.format {
+	case enums.JsonSeq:
+		return d.decodeSeq(v)
+	case enums.JsonArray:
+		return d.decodeArray(v)
+	default:
+		return d.decoder.Decode(v)
+	}
+}
+
+// decodeArray decodes the next element of a top-level JSON array.
+func (d *StreamDecoder) decodeArray(v any) error {
+	if !d.started {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("expected a JSON array, found %v", token)
+		}
+		d.started = true
+	}
+	if !d.decoder.More() {
+		if _, err := d.decoder.Token(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	return d.decoder.Decode(v)
+}
+
+// decodeSeq decodes the next JSON text of an RFC 7464 sequence, skipping empty records.
+func (d *StreamDecoder) decodeSeq(v any) error {
+	for {
+		record, err := d.reader.ReadBytes(recordSeparator)
+		if len(record) > 0 && record[len(record)-1] == recordSeparator {
+			record = record[:len(record)-1]
+		}
+		if !d.started {
+			// Anything before the first record separator is not part of a record
+			d.started = true
+			record = nil
+		}
+		if len(bytes.TrimSpace(record)) > 0 {
+			// A read error ending this record is reported again by the next call
+			return json.Unmarshal(record, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package network
+
+import (
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the minimum time between evictions of idle per-host token buckets.
+const sweepInterval = time.Minute
+
+// never is the wait reported for a token of a limit that adds no tokens.
+const never = time.Duration(1<<63 - 1)
+
+// RateLimit describes a token bucket: tokens are added at Rate per second up to Burst.
+type RateLimit struct {
+	// Rate is the number of requests allowed per second. A rate of zero or less adds no tokens,
+	// so requests beyond Burst fail with an *errors.RateLimitError, even in blocking mode.
+	Rate float64
+	// Burst is the maximum number of requests allowed at once. Values below 1 are treated as 1.
+	Burst int
+}
+
+// RateLimiterSettings configures a RateLimiter.
+// A request must obtain a token from every limit that applies to it.
+type RateLimiterSettings struct {
+	// Client limits all requests made through the limiter.
+	Client *RateLimit
+	// PerHost limits the requests to every host independently.
+	PerHost *RateLimit
+	// Hosts overrides PerHost for specific hosts, as found in the request URL (e.g. "api.example.com:8443").
+	Hosts map[string]RateLimit
+	// Endpoints limits the requests matching a pattern in path.Match syntax.
+	// Patterns starting with "/" are matched against the request path on any host,
+	// other patterns are matched against the host followed by the path (e.g. "api.example.com/v1/*").
+	Endpoints map[string]RateLimit
+	// NonBlocking makes requests fail fast with an *errors.RateLimitError instead of
+	// waiting for a token. Blocking requests wait at most until their context is done.
+	NonBlocking bool
+}
+
+// RateLimiter is a client-side token bucket rate limiter.
+// A single RateLimiter can be shared between clients so they draw from the same quotas.
+type RateLimiter struct {
+	settings  RateLimiterSettings
+	patterns  []string
+	client    *tokenBucket
+	mu        sync.Mutex
+	hosts     map[string]*tokenBucket
+	endpoints map[string]*tokenBucket
+	swept     time.Time
+}
+
+// limitedBucket is a token bucket together with the key reported when it is exhausted.
+type limitedBucket struct {
+	key    string
+	bucket *tokenBucket
+}
+
+// NewRateLimiter creates a rate limiter.
+//
+// Parameters:
+// - settings: The limiter settings.
+func NewRateLimiter(settings RateLimiterSettings) *RateLimiter {
+	limiter := &RateLimiter{
+		settings:  settings,
+		hosts:     map[string]*tokenBucket{},
+		endpoints: map[string]*tokenBucket{},
+	}
+	if settings.Client != nil {
+		limiter.client = newTokenBucket(*settings.Client)
+	}
+	for pattern := range settings.Endpoints {
+		limiter.patterns = append(limiter.patterns, pattern)
+	}
+	sort.Strings(limiter.patterns)
+	return limiter
+}
+
+// WithRateLimiter sets the rate limiter applied to every request made by the client.
+//
+// Parameters:
+// - limiter: The rate limiter to use.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(nc *networkClient) {
+		nc.limiter = limiter
+	}
+}
+
+// buckets returns the token buckets that apply to a request to the given host and path.
+func (l *RateLimiter) buckets(host string, urlPath string) []limitedBucket {
+	var buckets []limitedBucket
+	if l.client != nil {
+		buckets = append(buckets, limitedBucket{key: "client", bucket: l.client})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); now.Sub(l.swept) >= sweepInterval {
+		l.evictIdle(now)
+	}
+	if limit, ok := l.settings.Hosts[host]; ok || l.settings.PerHost != nil {
+		if !ok {
+			limit = *l.settings.PerHost
+		}
+		bucket, exists := l.hosts[host]
+		if !exists {
+			bucket = newTokenBucket(limit)
+			l.hosts[host] = bucket
+		}
+		buckets = append(buckets, limitedBucket{key: host, bucket: bucket})
+	}
+	for _, pattern := range l.patterns {
+		target := urlPath
+		if len(pattern) == 0 || pattern[0] != '/' {
+			target = host + urlPath
+		}
+		if matched, _ := path.Match(pattern, target); !matched {
+			continue
+		}
+		bucket, exists := l.endpoints[pattern]
+		if !exists {
+			bucket = newTokenBucket(l.settings.Endpoints[pattern])
+			l.endpoints[pattern] = bucket
+		}
+		buckets = append(buckets, limitedBucket{key: pattern, bucket: bucket})
+	}
+	return buckets
+}
+
+// evictIdle removes the per-host token buckets that are full again, which a new bucket would replace
+// identically, so hosts that are no longer called do not keep their buckets.
+// It must be called with l.mu held.
+func (l *RateLimiter) evictIdle(now time.Time) {
+	l.swept = now
+	for host, bucket := range l.hosts {
+		if bucket.full(now) {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// wait obtains a token from every limit that applies to the request.
+// In blocking mode it waits until the tokens are available, giving up with the
+// context error when the request context is done first.
+func (l *RateLimiter) wait(request *http.Request) error {
+	buckets := l.buckets(request.URL.Host, request.URL.Path)
+	now := time.Now()
+
+	if l.settings.NonBlocking {
+		for i, b := range buckets {
+			if ok, retryAfter := b.bucket.tryTake(now); !ok {
+				for _, taken := range buckets[:i] {
+					taken.bucket.refund()
+				}
+				return &errors.RateLimitError{Key: b.key, RetryAfter: retryAfter}
+			}
+		}
+		return nil
+	}
+
+	var delay time.Duration
+	var slowest string
+	for _, b := range buckets {
+		if wait := b.bucket.reserve(now); wait > delay {
+			delay, slowest = wait, b.key
+		}
+	}
+	if delay == 0 {
+		return nil
+	}
+	refund := func() {
+		for _, b := range buckets {
+			b.bucket.refund()
+		}
+	}
+	if delay == never {
+		// The limit adds no tokens, so waiting would block forever
+		refund()
+		return &errors.RateLimitError{Key: slowest, RetryAfter: delay}
+	}
+
+	ctx := request.Context()
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		refund()
+		return &errors.RateLimitError{Key: slowest, RetryAfter: delay}
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		refund()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// doWithRateLimit executes a single attempt of the request once the client's rate limiter allows it.
+func (nc networkClient) doWithRateLimit(request *http.Request) (*http.Response, error) {
+	if nc.limiter != nil {
+		if err := nc.limiter.wait(request); err != nil {
+			closeRequestBody(request)
+			return nil, err
+		}
+	}
+	return nc.doWithBreaker(request)
+}
+
+// tokenBucket is a token bucket whose tokens may go negative to represent reservations.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full token bucket for the given limit.
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(max(limit.Burst, 1))
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// advance adds the tokens accumulated since the last update.
+// It must be called with b.mu held.
+func (b *tokenBucket) advance(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+		b.last = now
+	}
+}
+
+// durationFor returns the time needed to accumulate the given number of missing tokens.
+func (b *tokenBucket) durationFor(tokens float64) time.Duration {
+	if b.rate <= 0 {
+		return never
+	}
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+// tryTake takes a token if one is available, otherwise it reports the time until one is.
+func (b *tokenBucket) tryTake(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, b.durationFor(1 - b.tokens)
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return b.durationFor(-b.tokens)
+}
+
+// full reports whether the bucket holds its burst of tokens, with no reservation outstanding.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(now)
+	return b.tokens >= b.burst
+}
+
+// refund returns a token taken by tryTake or reserve.
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+1, b.burst)
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"context"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultMaxRedirects is the number of redirects followed when the policy does not set one,
+// matching the default of http.Client.
+const defaultMaxRedirects = 10
+
+// sensitiveHeaders are the headers dropped when a redirect crosses a credential boundary,
+// the same ones http.Client drops.
+var sensitiveHeaders = []string{
+	constants.Authorization, constants.WwwAuthenticate, constants.Cookie, constants.Cookie2,
+	constants.ProxyAuthorization, constants.ProxyAuthenticate,
+}
+
+// RedirectPolicy configures how redirects are followed.
+// Every hop goes through the middleware chain, retries, circuit breaker and rate limiter.
+// Credential headers such as Authorization and Cookie are never forwarded to another host,
+// or from https to http.
+type RedirectPolicy struct {
+	// MaxRedirects is the maximum number of redirects followed. Defaults to 10.
+	// Requests redirected more often fail with an *errors.TooManyRedirectsError.
+	MaxRedirects int
+	// NoFollow disables following redirects, so 3xx responses are returned as they are.
+	NoFollow bool
+	// SameHostOnly stops following redirects to a host other than the one of the original request.
+	SameHostOnly bool
+}
+
+// Redirect describes a redirect response that was followed.
+type Redirect struct {
+	// StatusCode is the HTTP status code of the redirect response.
+	StatusCode int
+	// URL is the URL of the request that was redirected.
+	URL string
+	// Location is the resolved URL the request was redirected to.
+	Location string
+	// Header holds the headers of the redirect response.
+	Header http.Header
+}
+
+// redirectsKey is the context key holding the redirects followed to reach a response.
+type redirectsKey struct{}
+
+// WithRedirectPolicy sets the redirect policy for every request made by the client.
+// Without a policy, redirects are followed up to 10 times, unless the underlying
+// http.Client has its own CheckRedirect, which is then left in charge.
+//
+// Parameters:
+// - policy: The redirect policy to use.
+func WithRedirectPolicy(policy *RedirectPolicy) Option {
+	return func(nc *networkClient) {
+		nc.redirectPolicy = policy
+	}
+}
+
+// Redirects sets the redirect policy for the request, overriding the client's policy.
+// The redirects followed are reported in Response.Redirects.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - policy: The redirect policy to use.
+func (nc networkClient) Redirects(policy *RedirectPolicy) networkClient {
+	nc.redirectPolicy = policy
+	return nc
+}
+
+// followsRedirects checks if redirects are followed by the networkClient rather than the http.Client.
+func (nc networkClient) followsRedirects() bool {
+	return nc.redirectPolicy != nil || nc.client.CheckRedirect == nil
+}
+
+// httpClient returns the http.Client executing single requests, which does not follow
+// redirects when the networkClient follows them itself.
+func (nc networkClient) httpClient() *http.Client {
+	if !nc.followsRedirects() {
+		return nc.client
+	}
+	client := *nc.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+// doWithRedirects executes the request through the middleware chain, following redirects
+// according to the redirect policy. The redirects followed are recorded in the context
+// of the request of the final response.
+func (nc networkClient) doWithRedirects(request *http.Request) (*http.Response, error) {
+	if !nc.followsRedirects() {
+		return nc.doAuthenticated(request, true)
+	}
+	policy := nc.redirectPolicy
+	if policy == nil {
+		policy = &RedirectPolicy{}
+	}
+	maxRedirects := policy.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	original := request
+	var redirects []Redirect
+	for {
+		// Credentials are only sent to the host of the original request, and not over a downgraded scheme
+		res, err := nc.doAuthenticated(request, !crossesCredentialBoundary(original.URL, request.URL))
+		if err != nil || policy.NoFollow {
+			return res, err
+		}
+		next := nc.redirectRequest(original, request, res, policy)
+		if next == nil {
+			if len(redirects) > 0 && res.Request != nil {
+				res.Request = res.Request.WithContext(context.WithValue(res.Request.Context(), redirectsKey{}, redirects))
+			}
+			return res, nil
+		}
+		if len(redirects) == maxRedirects {
+			discardResponse(res)
+			return nil, &errors.TooManyRedirectsError{URL: original.URL.String(), MaxRedirects: maxRedirects}
+		}
+		redirects = append(redirects, Redirect{
+			StatusCode: res.StatusCode,
+			URL:        request.URL.String(),
+			Location:   next.URL.String(),
+			Header:     res.Header,
+		})
+		discardResponse(res)
+		request = next
+	}
+}
+
+// redirectRequest builds the request following a redirect response, or returns nil when the
+// response is not a redirect or the policy does not allow following it.
+// 307 and 308 redirects preserve the method and body, which must be replayable, while other
+// redirects switch to a GET without body, except for HEAD requests.
+//
+// Parameters:
+// - original: The request sent by the caller.
+// - request: The request that was redirected.
+// - res: The redirect response.
+// - policy: The redirect policy to apply.
+func (nc networkClient) redirectRequest(original *http.Request, request *http.Request, res *http.Response, policy *RedirectPolicy) *http.Request {
+	switch res.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil
+	}
+	location := res.Header.Get(constants.Location)
+	if location == "" {
+		return nil
+	}
+	target, err := request.URL.Parse(location)
+	if err != nil {
+		return nil
+	}
+	if policy.SameHostOnly && target.Host != original.URL.Host {
+		return nil
+	}
+
+	next := request.Clone(request.Context())
+	next.URL = target
+	next.Host = ""
+	if res.StatusCode == http.StatusTemporaryRedirect || res.StatusCode == http.StatusPermanentRedirect {
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil
+			}
+			next.Body = body
+		} else if request.Body != nil && request.Body != http.NoBody {
+			// The body was consumed and cannot be sent again
+			return nil
+		}
+	} else {
+		if request.Method != http.MethodHead {
+			next.Method = http.MethodGet
+		}
+		next.Body = http.NoBody
+		next.GetBody = nil
+		next.ContentLength = 0
+		next.Header.Del(constants.ContentType)
+		next.Header.Del(constants.ContentEncoding)
+		next.Header.Del(constants.ContentLength)
+	}
+	if crossesCredentialBoundary(request.URL, target) {
+		for _, header := range sensitiveHeaders {
+			next.Header.Del(header)
+		}
+	}
+	return next
+}
+
+// crossesCredentialBoundary checks if credentials sent to a URL must not be sent to the target URL,
+// which is the case when the target is on another host or downgrades https to http.
+//
+// Parameters:
+// - from: The URL the credentials are sent to.
+// - target: The URL the request is sent to next.
+func crossesCredentialBoundary(from *url.URL, target *url.URL) bool {
+	return target.Host != from.Host || (strings.EqualFold(from.Scheme, "https") && !strings.EqualFold(target.Scheme, "https"))
+}
+
+// redirectsOf returns the redirects followed to reach the response.
+func redirectsOf(res *http.Response) []Redirect {
+	if res.Request == nil {
+		return nil
+	}
+	redirects, _ := res.Request.Context().Value(redirectsKey{}).([]Redirect)
+	return redirects
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"github.com/xander1235/gorest/constants"
+	"net/http"
+	"time"
+)
+
+// Response holds the details of an HTTP response received by the networkClient.
+type Response struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the response headers.
+	Header http.Header
+	// Body holds the raw response body.
+	Body []byte
+	// URL is the final URL of the request, after any redirects were followed.
+	URL string
+	// RequestID is the X-Request-ID sent with the request.
+	RequestID string
+	// Duration is the time taken from sending the request until the body was read.
+	Duration time.Duration
+	// HedgeAttempt is the 1-based number of the hedged attempt that produced the response,
+	// or 0 when the request was not hedged.
+	HedgeAttempt int
+	// Redirects lists the redirects followed to reach the response, in order.
+	Redirects []Redirect
+}
+
+// newResponse creates a Response from the HTTP response and its body.
+//
+// Parameters:
+// - request: The HTTP request that was sent.
+// - res: The HTTP response received.
+// - body: The raw response body.
+// - duration: The time taken by the request.
+func newResponse(request *http.Request, res *http.Response, body []byte, duration time.Duration) *Response {
+	response := &Response{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header,
+		Body:         body,
+		URL:          request.URL.String(),
+		RequestID:    request.Header.Get(constants.XRequestId),
+		Duration:     duration,
+		HedgeAttempt: hedgeAttempt(res),
+		Redirects:    redirectsOf(res),
+	}
+	// The request ID is set with its non-canonical key, which Header.Get does not find
+	if ids := request.Header[constants.XRequestId]; len(ids) > 0 {
+		response.RequestID = ids[0]
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		response.URL = res.Request.URL.String()
+	}
+	return response
+}
+
+// String returns the raw response body as a string.
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// hasNoContent checks if the response carries no body to decode.
+func hasNoContent(request *http.Request, res *http.Response) bool {
+	return res.StatusCode == http.StatusNoContent || request.Method == http.MethodHead
+}
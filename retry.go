@@ -0,0 +1,230 @@
+package network
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried.
+// A policy can be set for every request made by a client with WithRetryPolicy,
+// or for a single request with the Retry method.
+//
+// Example:
+// policy := DefaultRetryPolicy()
+// policy.MaxAttempts = 5
+// client := New(WithRetryPolicy(policy))
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+	// BaseDelay is the backoff delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay between attempts.
+	MaxDelay time.Duration
+	// RetryNonIdempotent allows retrying methods that are not idempotent, such as POST and PATCH.
+	RetryNonIdempotent bool
+	// RetryableSeries lists the status series (e.g. enums.ServerError) that are retried.
+	RetryableSeries []enums.HttpStatus
+	// RetryableStatuses lists individual status codes (e.g. enums.TooManyRequests) that are retried.
+	RetryableStatuses []enums.HttpStatus
+	// Budget limits the overall amount of retries. It may be shared between policies.
+	// A nil budget does not limit retries.
+	Budget *RetryBudget
+}
+
+// DefaultRetryPolicy returns a policy making up to 3 attempts of idempotent requests
+// on transport errors, 429, 502, 503 and 504 responses, with a fresh retry budget.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		RetryableStatuses: []enums.HttpStatus{
+			enums.TooManyRequests,
+			enums.BadGateway,
+			enums.ServiceUnavailable,
+			enums.GatewayTimeout,
+		},
+		Budget: NewRetryBudget(10, 0.1),
+	}
+}
+
+// RetryBudget limits retries across requests so a failing downstream cannot cause a retry storm.
+// It follows the gRPC retry throttling scheme: every failed attempt removes a token,
+// every successful attempt adds tokenRatio tokens, and retries are only allowed
+// while more than half of the tokens are available.
+type RetryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	tokenRatio float64
+}
+
+// NewRetryBudget creates a retry budget.
+//
+// Parameters:
+// - maxTokens: The number of tokens the budget starts with and can hold.
+// - tokenRatio: The number of tokens added back for every successful attempt.
+func NewRetryBudget(maxTokens int, tokenRatio float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:     float64(maxTokens),
+		maxTokens:  float64(maxTokens),
+		tokenRatio: tokenRatio,
+	}
+}
+
+// onFailure records a retryable failure and reports whether a retry is still allowed.
+func (b *RetryBudget) onFailure() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Max(b.tokens-1, 0)
+	return b.tokens > b.maxTokens/2
+}
+
+// onSuccess records a successful attempt.
+func (b *RetryBudget) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.tokens+b.tokenRatio, b.maxTokens)
+}
+
+// Retry sets the retry policy for the request, overriding the client's policy.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - policy: The retry policy to use.
+func (nc networkClient) Retry(policy *RetryPolicy) networkClient {
+	nc.retryPolicy = policy
+	return nc
+}
+
+// WithRetryPolicy sets the retry policy for every request made by the client.
+//
+// Parameters:
+// - policy: The retry policy to use.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(nc *networkClient) {
+		nc.retryPolicy = policy
+	}
+}
+
+// allows checks if requests with the given method may be retried.
+func (p *RetryPolicy) allows(method string) bool {
+	return p.MaxAttempts > 1 && (p.RetryNonIdempotent || enums.HttpMethods(method).IsIdempotent())
+}
+
+// isRetryable checks if the outcome of an attempt should be retried.
+func (p *RetryPolicy) isRetryable(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		var circuitOpen *errors.CircuitOpenError
+		var rateLimited *errors.RateLimitError
+		return ctx.Err() == nil && !stderrors.As(err, &circuitOpen) && !stderrors.As(err, &rateLimited)
+	}
+	status := enums.HttpStatus(res.StatusCode)
+	for _, series := range p.RetryableSeries {
+		if status.SeriesType() == series {
+			return true
+		}
+	}
+	for _, code := range p.RetryableStatuses {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry using exponential backoff with full jitter.
+// A Retry-After header on 429 and 503 responses takes precedence.
+func (p *RetryPolicy) backoff(retry int, res *http.Response) time.Duration {
+	if res != nil && (res.StatusCode == int(enums.TooManyRequests) || res.StatusCode == int(enums.ServiceUnavailable)) {
+		if delay, ok := parseRetryAfter(res.Header.Get(constants.RetryAfter)); ok {
+			return delay
+		}
+	}
+	ceiling := float64(p.BaseDelay) * math.Pow(2, float64(retry-1))
+	if p.MaxDelay > 0 {
+		ceiling = math.Min(ceiling, float64(p.MaxDelay))
+	}
+	if ceiling < 1 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// doWithRetry executes the request, retrying it according to the retry policy.
+// The request body is replayed with GetBody, so requests with bodies that cannot
+// be replayed are sent only once.
+func (nc networkClient) doWithRetry(request *http.Request) (*http.Response, error) {
+	policy := nc.retryPolicy
+	if policy == nil || !policy.allows(request.Method) {
+		return nc.doWithHedging(request)
+	}
+	replayable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	ctx := request.Context()
+
+	attemptRequest := request
+	for attempt := 1; ; attempt++ {
+		res, err := nc.doWithHedging(attemptRequest)
+		if !policy.isRetryable(ctx, res, err) {
+			policy.Budget.onSuccess()
+			return res, err
+		}
+		if !policy.Budget.onFailure() || attempt >= policy.MaxAttempts || !replayable {
+			return res, err
+		}
+
+		delay := policy.backoff(attempt, res)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return res, err
+		}
+		if res != nil {
+			discardResponse(res)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		attemptRequest = request.Clone(ctx)
+		if request.GetBody != nil {
+			attemptRequest.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
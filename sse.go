@@ -0,0 +1,295 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event represents a Server-Sent Event received from a text/event-stream response.
+type Event struct {
+	// ID is the event ID, which is sent back as Last-Event-ID when reconnecting.
+	ID string
+	// Event is the event type. It is "message" when the server did not set one.
+	Event string
+	// Data holds the event data, with multiple data lines joined by newlines.
+	Data string
+	// Retry is the reconnection delay requested by the server with this event, if any.
+	Retry time.Duration
+}
+
+// EventSource configures how Server-Sent Events are consumed.
+type EventSource struct {
+	// ReconnectDelay is the delay before reconnecting when the server did not send a retry field.
+	// Defaults to 3 seconds.
+	ReconnectDelay time.Duration
+	// MaxReconnects is the number of consecutive failed connections after which Subscribe gives up.
+	// Zero reconnects forever, a negative value never reconnects.
+	MaxReconnects int
+	// LastEventID is sent as Last-Event-ID on the first connection, to resume an earlier stream.
+	LastEventID string
+}
+
+// sseCallbacks are the functions called while reading an event stream.
+type sseCallbacks struct {
+	// retry is called with the reconnection delay as soon as a retry field is parsed,
+	// including in blocks without data that dispatch no event.
+	retry func(time.Duration)
+	// lastEventID is called with the last event ID at the end of every block,
+	// including blocks without data that dispatch no event.
+	lastEventID func(string)
+	// handler is called for every dispatched event.
+	handler func(Event) error
+}
+
+// Subscribe connects to a Server-Sent Events endpoint with a GET request and calls the handler
+// for every event received. The request shares the client's host, headers, middleware and context.
+// When the stream ends, the connection fails or the server answers with a 5xx status, it reconnects
+// after the reconnection delay, sending the last event ID set by the server as Last-Event-ID.
+// Subscribe blocks until the context is done, the server answers 204 No Content,
+// the handler returns an error, or reconnection is given up.
+// The timeout of the underlying http.Client also bounds each connection, so long-lived
+// subscriptions should use a client without a timeout.
+//
+// Example:
+// err := client.WithContext(ctx).Subscribe("/events", EventSource{}, func(event Event) error {
+// log.Println(event.Event, event.Data)
+// return nil
+// })
+//
+// Parameters:
+// - endpoint: The endpoint to subscribe to.
+// - source: The reconnection settings.
+// - handler: The function called for every event.
+func (nc networkClient) Subscribe(endpoint string, source EventSource, handler func(Event) error) *errors.ErrorDetails {
+	ctx := nc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	delay := source.ReconnectDelay
+	if delay <= 0 {
+		delay = 3 * time.Second
+	}
+	lastEventID := source.LastEventID
+	failures := 0
+
+	for {
+		received, reconnect, appErr := nc.subscribeOnce(endpoint, lastEventID, sseCallbacks{
+			retry: func(retry time.Duration) {
+				if retry > 0 {
+					delay = retry
+				}
+			},
+			lastEventID: func(id string) {
+				lastEventID = id
+			},
+			handler: handler,
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !reconnect {
+			return appErr
+		}
+		if received {
+			failures = 0
+		} else {
+			failures++
+		}
+		if source.MaxReconnects < 0 || (source.MaxReconnects > 0 && failures > source.MaxReconnects) {
+			if appErr == nil {
+				appErr = exceptions.GenericException(constants.StreamClosed, constants.StreamClosed, 500)
+			}
+			return appErr
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// SubscribeChannel connects to a Server-Sent Events endpoint like Subscribe and delivers
+// the events on the returned channel. The event channel is closed when the subscription ends,
+// after which the error channel receives the reason, or nil when the context was done.
+//
+// Parameters:
+// - endpoint: The endpoint to subscribe to.
+// - source: The reconnection settings.
+func (nc networkClient) SubscribeChannel(endpoint string, source EventSource) (<-chan Event, <-chan *errors.ErrorDetails) {
+	ctx := nc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	events := make(chan Event)
+	done := make(chan *errors.ErrorDetails, 1)
+	go func() {
+		defer close(done)
+		appErr := nc.Subscribe(endpoint, source, func(event Event) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(events)
+		done <- appErr
+	}()
+	return events, done
+}
+
+// subscribeOnce opens a single event stream and reads it until it ends.
+// It reports whether any event was received and whether the stream may be reconnected.
+//
+// Parameters:
+// - endpoint: The endpoint to subscribe to.
+// - lastEventID: The ID of the last event received, if any.
+// - callbacks: The functions called with the events and fields of the stream.
+func (nc networkClient) subscribeOnce(endpoint string, lastEventID string, callbacks sseCallbacks) (bool, bool, *errors.ErrorDetails) {
+	headers := make(map[string]string, len(nc.headers)+3)
+	for key, value := range nc.headers {
+		headers[key] = value
+	}
+	headers[constants.Accept] = constants.EventStream
+	headers[constants.CacheControl] = "no-cache"
+	if lastEventID != "" {
+		headers[constants.LastEventId] = lastEventID
+	}
+	nc.headers = headers
+	nc.body = nil
+
+	res, appErr := nc.Stream(enums.GET, endpoint)
+	if appErr != nil {
+		// Reconnect on network failures and server errors, fail on anything else
+		return false, res == nil || enums.HttpStatus(res.StatusCode).Is5XXSeries(), appErr
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(res.Body)
+
+	if res.StatusCode == 204 {
+		return false, false, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get(constants.ContentType)); mediaType != constants.EventStream {
+		return false, false, exceptions.GenericException(constants.InvalidResponseType, mediaType, res.StatusCode)
+	}
+
+	received := false
+	var handlerErr error
+	handler := callbacks.handler
+	callbacks.handler = func(event Event) error {
+		received = true
+		handlerErr = handler(event)
+		return handlerErr
+	}
+	err := readEvents(res.Body, lastEventID, callbacks)
+	if handlerErr != nil {
+		return received, false, exceptions.GenericException(handlerErr.Error(), handlerErr, 500)
+	}
+	if err != nil {
+		return received, true, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, 500)
+	}
+	return received, true, nil
+}
+
+// readEvents parses a text/event-stream body and calls the handler for every dispatched event.
+// It returns nil when the stream ends, or the error of the handler or reader.
+//
+// Parameters:
+// - body: The event stream.
+// - lastID: The ID of the last event received before this stream, which events inherit until the server sets a new one.
+// - callbacks: The functions called with the events and fields of the stream.
+func readEvents(body io.Reader, lastID string, callbacks sseCallbacks) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	scanner.Split(scanEventLines)
+
+	var event Event
+	var data strings.Builder
+	hasData := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event, and sets the last event ID even when there is no data
+			callbacks.lastEventID(lastID)
+			if hasData {
+				event.ID = lastID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				if err := callbacks.handler(event); err != nil {
+					return err
+				}
+			}
+			event = Event{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comments are used as keep-alives
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastID = value
+			}
+		case "retry":
+			if millis, err := strconv.Atoi(value); err == nil && millis >= 0 {
+				event.Retry = time.Duration(millis) * time.Millisecond
+				callbacks.retry(event.Retry)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// scanEventLines is a bufio.SplitFunc splitting on CRLF, LF or CR line endings.
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		switch b {
+		case '\n':
+			return i + 1, data[:i], nil
+		case '\r':
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i], nil
+				}
+				return i + 1, data[:i], nil
+			}
+			if atEOF {
+				return i + 1, data[:i], nil
+			}
+			// Wait for the next byte to tell CR from CRLF
+			return 0, nil, nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		// A trailing line without a line ending is discarded, as the event is incomplete
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
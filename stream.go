@@ -0,0 +1,125 @@
+package network
+
+import (
+	"github.com/xander1235/gorest/cache"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/exceptions"
+	"github.com/xander1235/gorest/exceptions/errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// StreamResponse holds the details of an HTTP response whose body is read by the caller.
+// The caller must close Body to release the connection.
+type StreamResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the response headers.
+	Header http.Header
+	// URL is the final URL of the request, after any redirects were followed.
+	URL string
+	// RequestID is the X-Request-ID sent with the request.
+	RequestID string
+	// Duration is the time taken from sending the request until the response headers were received.
+	Duration time.Duration
+	// HedgeAttempt is the 1-based number of the hedged attempt that produced the response,
+	// or 0 when the request was not hedged.
+	HedgeAttempt int
+	// Redirects lists the redirects followed to reach the response, in order.
+	Redirects []Redirect
+	// Body streams the response body. It is never nil.
+	Body io.ReadCloser
+}
+
+// Stream sends an HTTP request and returns the response without reading its body,
+// so large bodies can be consumed incrementally. The status and headers are available
+// as soon as the response headers are received.
+//
+// Non-2xx responses are read and closed, and reported as ErrorDetails in the same way
+// as Execute, with the StreamResponse body set to http.NoBody. On success the caller
+// must close the StreamResponse body to release the connection.
+// Note that the timeout of the underlying http.Client also bounds reading the body,
+// so long downloads should use a client without a timeout and rely on the context instead.
+//
+// Example:
+// res, err := client.Stream(enums.GET, "/exports/1")
+// if err == nil {
+// defer res.Body.Close()
+// _, copyErr := io.Copy(file, res.Body)
+// }
+//
+// Parameters:
+// - method: The HTTP method to use (GET, POST, etc.).
+// - endpoint: The endpoint to send the request to.
+func (nc networkClient) Stream(method enums.HttpMethods, endpoint string) (*StreamResponse, *errors.ErrorDetails) {
+	request, appErr := nc.newRequest(method, endpoint)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return nc.streamRequest(request)
+}
+
+// StreamTo sends an HTTP request and passes the streamed response to the consumer.
+// The body is closed when the consumer returns, whether or not it was fully read.
+// An error returned by the consumer is reported as ErrorDetails holding that error.
+//
+// Parameters:
+// - method: The HTTP method to use (GET, POST, etc.).
+// - endpoint: The endpoint to send the request to.
+// - consumer: The function reading the response.
+func (nc networkClient) StreamTo(method enums.HttpMethods, endpoint string, consumer func(*StreamResponse) error) *errors.ErrorDetails {
+	res, appErr := nc.Stream(method, endpoint)
+	if appErr != nil {
+		return appErr
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(res.Body)
+
+	if err := consumer(res); err != nil {
+		return exceptions.GenericException(err.Error(), err, 500)
+	}
+	return nil
+}
+
+// streamRequest executes the request through the middleware chain without reading a successful body.
+//
+// Parameters:
+// - request: The HTTP request to send.
+func (nc networkClient) streamRequest(request *http.Request) (*StreamResponse, *errors.ErrorDetails) {
+	start := time.Now()
+	// Streamed bodies are read as they arrive, so they are never buffered by the cache
+	request = request.WithContext(cache.WithoutCache(request.Context()))
+	res, err := nc.doWithRedirects(request)
+	if err != nil {
+		return nil, transportException(err)
+	}
+
+	response := newResponse(request, res, nil, time.Since(start))
+	streamed := &StreamResponse{
+		StatusCode:   response.StatusCode,
+		Header:       response.Header,
+		URL:          response.URL,
+		RequestID:    response.RequestID,
+		Duration:     response.Duration,
+		HedgeAttempt: response.HedgeAttempt,
+		Redirects:    response.Redirects,
+		Body:         res.Body,
+	}
+	if enums.HttpStatus(res.StatusCode).Is2XXSeries() {
+		return streamed, nil
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(res.Body)
+	streamed.Body = http.NoBody
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return streamed, exceptions.GenericException(err.Error(), constants.SomethingWentWrong, res.StatusCode)
+	}
+	nc.response = nil
+	return streamed, nc.handleResponse(request, res, bodyBytes)
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"context"
+	"github.com/xander1235/gorest/constants"
+	"github.com/xander1235/gorest/constants/enums"
+	"github.com/xander1235/gorest/parsers"
+	"io"
+	"mime"
+)
+
+// StreamFormat sets the format used to decode streamed JSON values with ForEach and Iterate.
+// When it is not set, the format is derived from the response Content-Type: NDJSON media types
+// and application/json-seq are decoded as such, anything else as a top-level JSON array.
+// This method is chainable and returns the updated networkClient.
+//
+// Parameters:
+// - format: The stream format to use.
+func (nc networkClient) StreamFormat(format enums.StreamFormat) networkClient {
+	nc.streamFormat = format
+	return nc
+}
+
+// Iterator decodes the values of a streamed JSON response one at a time.
+// It must be closed to release the connection.
+//
+// Example:
+// it, err := Iterate[LogLine](ctx, client, enums.GET, "/logs", nil)
+// if err != nil { return err }
+// defer it.Close()
+// for it.Next() { fmt.Println(it.Value()) }
+// return it.Err()
+type Iterator[T any] struct {
+	ctx      context.Context
+	response *StreamResponse
+	decoder  *parsers.StreamDecoder
+	value    T
+	err      error
+}
+
+// Iterate sends a request with the client and returns an iterator over the streamed JSON values
+// of the response, decoded as values of type T. A nil body keeps the body configured on the client.
+// A nil client uses NetworkClient, and a nil context the context of the client or context.Background().
+//
+// Parameters:
+// - ctx: The context of the request. Iteration stops when it is done.
+// - client: The client to send the request with.
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+func Iterate[T any](ctx context.Context, client Client, method enums.HttpMethods, endpoint string, body any) (*Iterator[T], error) {
+	nc := resolveClient(client)
+	if ctx == nil {
+		ctx = nc.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	nc = nc.WithContext(ctx)
+	if body != nil {
+		nc = nc.Body(body)
+	}
+	res, appErr := nc.Stream(method, endpoint)
+	if appErr != nil {
+		return nil, appErr.AsError()
+	}
+
+	format := nc.streamFormat
+	if format == enums.StreamFormatType {
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get(constants.ContentType))
+		format = format.ValueOf(mediaType)
+		if format != enums.NDJson && format != enums.JsonSeq {
+			format = enums.JsonArray
+		}
+	}
+	return &Iterator[T]{
+		ctx:      ctx,
+		response: res,
+		decoder:  parsers.NewStreamDecoder(res.Body, format),
+	}, nil
+}
+
+// ForEach sends a request with the client and calls fn with every streamed JSON value of the response,
+// decoded as a value of type T. It stops at the end of the stream, when the context is done
+// or when fn returns an error, which is then returned. A nil client uses NetworkClient.
+//
+// Example:
+// err := ForEach[Document](ctx, client, enums.GET, "/export", nil, func(doc Document) error {
+// return index(doc)
+// })
+//
+// Parameters:
+// - ctx: The context of the request.
+// - client: The client to send the request with.
+// - method: The HTTP method to use.
+// - endpoint: The endpoint to send the request to.
+// - body: The body of the request.
+// - fn: The function called with every value.
+func ForEach[T any](ctx context.Context, client Client, method enums.HttpMethods, endpoint string, body any, fn func(T) error) error {
+	it, err := Iterate[T](ctx, client, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer func(it *Iterator[T]) {
+		_ = it.Close() // Intentionally ignoring error as we can't do much with it during deferred close
+	}(it)
+
+	for it.Next() {
+		if err = fn(it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Next decodes the next value, reporting whether there is one.
+// It returns false at the end of the stream, on a decoding error or when the context is done.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	var value T
+	if err := it.decoder.Decode(&value); err != nil {
+		if ctxErr := it.ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		it.err = err
+		return false
+	}
+	it.value = value
+	return true
+}
+
+// Value returns the value decoded by the last call to Next.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, or nil if the stream ended normally.
+func (it *Iterator[T]) Err() error {
+	if it.err == io.EOF {
+		return nil
+	}
+	return it.err
+}
+
+// Response returns the details of the streamed response.
+func (it *Iterator[T]) Response() *StreamResponse {
+	return it.response
+}
+
+// Close closes the response body, releasing the connection.
+func (it *Iterator[T]) Close() error {
+	return it.response.Body.Close()
+}
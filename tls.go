@@ -0,0 +1,204 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSSettings configures the TLS connections of a client, including mutual TLS.
+type TLSSettings struct {
+	// CertFile and KeyFile are the paths of the PEM encoded client certificate and private key.
+	// KeyFile defaults to CertFile, for files holding both. The files are reloaded when they
+	// change, so rotated certificates are used without a restart.
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM are the PEM encoded client certificate and private key, used instead of files.
+	CertPEM []byte
+	KeyPEM  []byte
+	// RootCAFiles and RootCAPEM are PEM encoded certificate authorities trusted to verify the server,
+	// replacing the system roots. The system roots are used when neither is set.
+	RootCAFiles []string
+	RootCAPEM   []byte
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13. Defaults to TLS 1.2.
+	MinVersion uint16
+	// ServerName overrides the name sent with SNI and verified in the server certificate,
+	// e.g. when connecting to a service by IP address.
+	ServerName string
+	// ReloadInterval is the minimum time between checks of CertFile and KeyFile for changes,
+	// which happen when new connections are established. Defaults to 1 minute, and a negative
+	// interval disables reloading.
+	ReloadInterval time.Duration
+}
+
+// certificateReloader loads a client certificate from files and reloads it when the files change.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+	checked  time.Time
+}
+
+// NewTLSConfig creates the TLS configuration described by the settings, to be set with WithTLSConfig.
+// The client certificate and the certificate authorities are loaded immediately, so invalid files
+// or PEM data are reported here rather than when connecting.
+//
+// Parameters:
+// - settings: The TLS settings.
+func NewTLSConfig(settings TLSSettings) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: settings.MinVersion,
+		ServerName: settings.ServerName,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	switch {
+	case settings.CertFile != "" && len(settings.CertPEM) > 0:
+		return nil, fmt.Errorf("client certificate is set both as a file and as PEM data")
+	case settings.CertFile != "":
+		interval := settings.ReloadInterval
+		if interval == 0 {
+			interval = time.Minute
+		}
+		keyFile := settings.KeyFile
+		if keyFile == "" {
+			keyFile = settings.CertFile
+		}
+		reloader := &certificateReloader{certFile: settings.CertFile, keyFile: keyFile, interval: interval}
+		if err := reloader.reload(time.Now()); err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = reloader.getClientCertificate
+	case len(settings.CertPEM) > 0:
+		cert, err := tls.X509KeyPair(settings.CertPEM, settings.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(settings.RootCAFiles) > 0 || len(settings.RootCAPEM) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		for _, file := range settings.RootCAFiles {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if !config.RootCAs.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificate found in %s", file)
+			}
+		}
+		if len(settings.RootCAPEM) > 0 && !config.RootCAs.AppendCertsFromPEM(settings.RootCAPEM) {
+			return nil, fmt.Errorf("no certificate found in the root CA PEM data")
+		}
+	}
+	return config, nil
+}
+
+// WithTLSConfig sets the TLS configuration of the client, e.g. created by NewTLSConfig, keeping the
+// other settings of its HTTP client and transport. It applies whatever the order of the options,
+// to a copy of the transport of the HTTP client, which must be an *http.Transport or nil; other
+// transports, such as APM wrappers, must be configured with the TLS configuration directly, and
+// every request of a client combining them with WithTLSConfig fails rather than being sent without it.
+//
+// Parameters:
+// - config: The TLS configuration to use.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(nc *networkClient) {
+		nc.tlsConfig = config
+	}
+}
+
+// withTLSConfig returns a copy of the HTTP client whose transport uses the TLS configuration.
+// When its transport cannot be configured, the transport of the copy fails every request instead.
+//
+// Parameters:
+// - client: The HTTP client to configure.
+// - config: The TLS configuration to use.
+func withTLSConfig(client *http.Client, config *tls.Config) *http.Client {
+	configured := *client
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		configured.Transport = failingTransport{err: fmt.Errorf("cannot apply the TLS configuration to a transport of type %T", t)}
+		return &configured
+	}
+	tlsConfig := config.Clone()
+	if len(tlsConfig.NextProtos) == 0 && transport.TLSClientConfig != nil {
+		// Keeps the protocols negotiated by the transport, such as HTTP/2
+		tlsConfig.NextProtos = transport.TLSClientConfig.NextProtos
+	}
+	transport.TLSClientConfig = tlsConfig
+	configured.Transport = transport
+	return &configured
+}
+
+// failingTransport is an http.RoundTripper failing every request with the same error.
+type failingTransport struct {
+	err error
+}
+
+// RoundTrip fails the request with the error of the transport.
+//
+// Parameters:
+// - request: The HTTP request to fail.
+func (t failingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	closeRequestBody(request)
+	return nil, t.err
+}
+
+// getClientCertificate returns the client certificate, reloading it first when the files changed.
+// A certificate that fails to load, e.g. while the files are being rotated, is retried at the next
+// check and the previous certificate is used in the meantime.
+func (r *certificateReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.interval > 0 && now.Sub(r.checked) >= r.interval {
+		_ = r.reloadLocked(now) // Intentionally ignoring error as the previous certificate is still valid
+	}
+	return r.cert, nil
+}
+
+// reload loads the certificate from the files.
+func (r *certificateReloader) reload(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reloadLocked(now)
+}
+
+// reloadLocked loads the certificate from the files when they changed since the last load.
+// The caller must hold r.mu.
+func (r *certificateReloader) reloadLocked(now time.Time) error {
+	r.checked = now
+	var modTimes [2]time.Time
+	for i, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	if r.cert != nil && modTimes == r.modTimes {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert, r.modTimes = &cert, modTimes
+	return nil
+}
@@ -0,0 +1,21 @@
+package types
+
+import "net/http"
+
+// Handler executes a built HTTP request and returns its response.
+type Handler func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to run logic before and after the request is executed.
+// A middleware may modify the request, short-circuit it by returning without
+// calling next, or inspect and replace the response.
+//
+// Example:
+//
+//	logging := func(next types.Handler) types.Handler {
+//		return func(request *http.Request) (*http.Response, error) {
+//			res, err := next(request)
+//			log.Println(request.Method, request.URL)
+//			return res, err
+//		}
+//	}
+type Middleware func(next Handler) Handler
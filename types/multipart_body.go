@@ -83,6 +83,7 @@ func (b *MultipartBody) AddFile(name string, value os.File) {
 }
 
 // CreateBuffer creates a buffer for the multipart body.
+// Every part, including file contents, is copied into memory.
 //
 // Returns:
 // - A pointer to the buffer.
@@ -94,113 +95,220 @@ func (b *MultipartBody) CreateBuffer() (*bytes.Buffer, string, error) {
 	// Create a new writer for the multipart body
 	writer := multipart.NewWriter(buf)
 
+	// Write the parts and close the writer
+	err := b.writeParts(writer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Return the buffer and content type
+	return buf, writer.FormDataContentType(), nil
+}
+
+// CreateStream creates a reader that streams the multipart body while it is read,
+// so file contents are never held in memory. The parts are written from a separate
+// goroutine through an io.Pipe; closing the reader stops the writing and closes the files.
+//
+// Returns:
+// - A reader for the body, which must be read to the end or closed.
+// - The content type of the body.
+// - The length of the body, or -1 if the size of a part is not known in advance.
+// - An error if the creation fails.
+func (b *MultipartBody) CreateStream() (io.ReadCloser, string, int64, error) {
+	reader, pipeWriter := io.Pipe()
+	// Create a new writer for the multipart body
+	writer := multipart.NewWriter(pipeWriter)
+
+	// Compute the length with the same boundary before any part is consumed
+	contentLength, err := b.contentLength(writer.Boundary())
+	if err != nil {
+		return nil, "", -1, err
+	}
+
+	go func() {
+		// Closing the pipe with the error makes it visible to the reader
+		_ = pipeWriter.CloseWithError(b.writeParts(writer))
+	}()
+
+	return reader, writer.FormDataContentType(), contentLength, nil
+}
+
+// writeParts writes every part to the writer and closes the writer.
+// Files are closed once written, or when writing fails.
+//
+// Parameters:
+// - writer: The multipart writer to write to.
+func (b *MultipartBody) writeParts(writer *multipart.Writer) error {
 	// Iterate over the parts
-	for _, part := range b.Parts {
-		// Create a new header for the part
-		h := make(textproto.MIMEHeader)
-		h.Set(constants.ContentDisposition, fmt.Sprintf(`form-data; name="%s"`, part.Name))
-		
-		// Set the content type if required
-		if part.IncludeContentType {
-			h.Set(constants.ContentType, part.ContentType)
+	for i, part := range b.Parts {
+		// Create a new writer for the part
+		partWriter, err := part.createWriter(writer)
+		if err != nil {
+			b.closeFiles(i)
+			return err
 		}
 
-		// Create a new writer for the part
+		// Write the value to the part
 		switch part.ContentType {
 		case "text/plain":
-			// Create a new writer for the part 
-			partWriter, err := writer.CreatePart(h)
-			if err != nil {
-				return nil, "", err
-			}
-
-			// Write the value to the part
 			_, err = partWriter.Write([]byte(part.Value.(string)))
-			if err != nil {
-				return nil, "", err
-			}
 		case "application/json":
-			// Create a new writer for the part
-			partWriter, err := writer.CreatePart(h)
-			if err != nil {
-				return nil, "", err
-			}
 			//var jsonBytes bytes.Buffer
 			jsonBytes, marshalErr := json.Marshal(part.Value)
 			if marshalErr != nil {
-				return nil, "", marshalErr
+				b.closeFiles(i + 1)
+				return marshalErr
 			}
-
-			// Write the value to the part
 			_, err = partWriter.Write(jsonBytes)
-			if err != nil {
-				return nil, "", err
-			}
 		case "osFile":
-			// Create a new writer for the part
 			v := part.Value.(os.File)
-			fileInfo, err := v.Stat()
-			if err != nil {
-				return nil, "", err
-			}
-			h.Set(constants.ContentDisposition, fmt.Sprintf(`form-data; name="%s"; filename="%s"`, part.Name, fileInfo.Name()))
-			
-			// Create a new writer for the part
-			partWriter, err := writer.CreateFormFile(part.Name, fileInfo.Name())
-			if err != nil {
-				return nil, "", err
-			}
-			
-			// Write the value to the part
 			_, err = io.Copy(partWriter, &v)
-			if err != nil {
-				return nil, "", err
+			closeErr := v.Close() // Manually close the file here
+			if err == nil {
+				err = closeErr
 			}
-			err = v.Close()
-			if err != nil {
-				return nil, "", err
-			} // Manually close the file here
 		case "file":
-			// Create a new writer for the part
-			v := part.Value.(multipart.FileHeader)
-			h.Set(constants.ContentDisposition, fmt.Sprintf(`form-data; name="%s"; filename="%s"`, part.Name, v.Filename))
-			
-			// Create a new writer for the part
-			partWriter, err := writer.CreateFormFile(part.Name, v.Filename)
-			if err != nil {
-				return nil, "", err
-			}
-			
 			// Open the file
-			file, err := v.Open()
-			if err != nil {
-				return nil, "", err
+			v := part.Value.(multipart.FileHeader)
+			file, openErr := v.Open()
+			if openErr != nil {
+				b.closeFiles(i + 1)
+				return openErr
 			}
-			
+
 			// Write the file to the part
 			_, err = io.Copy(partWriter, file)
-			if err != nil {
-				err = file.Close()
-				if err != nil {
-					return nil, "", err
-				} // Ensure file is closed before returning
-				return nil, "", err
+			closeErr := file.Close() // Manually close the file here
+			if err == nil {
+				err = closeErr
 			}
-			err = file.Close()
-			if err != nil {
-				return nil, "", err
-			} // Manually close the file here
-		default:
-			return nil, "", fmt.Errorf("unsupported part type: %T", part.ContentType)
+		}
+		if err != nil {
+			b.closeFiles(i + 1)
+			return err
 		}
 	}
 
 	// Close the writer
-	err := writer.Close()
+	return writer.Close()
+}
+
+// createWriter creates the header of the part in the writer and returns the writer for its content.
+//
+// Parameters:
+// - writer: The multipart writer to write to.
+func (part Part) createWriter(writer *multipart.Writer) (io.Writer, error) {
+	// Create a new header for the part
+	h := make(textproto.MIMEHeader)
+	h.Set(constants.ContentDisposition, fmt.Sprintf(`form-data; name="%s"`, part.Name))
+
+	// Set the content type if required
+	if part.IncludeContentType {
+		h.Set(constants.ContentType, part.ContentType)
+	}
+
+	switch part.ContentType {
+	case "text/plain", "application/json":
+		return writer.CreatePart(h)
+	case "osFile":
+		v := part.Value.(os.File)
+		fileInfo, err := v.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return writer.CreateFormFile(part.Name, fileInfo.Name())
+	case "file":
+		v := part.Value.(multipart.FileHeader)
+		return writer.CreateFormFile(part.Name, v.Filename)
+	default:
+		return nil, fmt.Errorf("unsupported part type: %T", part.ContentType)
+	}
+}
+
+// size returns the length of the content of the part, or -1 if it is not known in advance.
+func (part Part) size() (int64, error) {
+	switch part.ContentType {
+	case "text/plain":
+		return int64(len(part.Value.(string))), nil
+	case "application/json":
+		jsonBytes, err := json.Marshal(part.Value)
+		if err != nil {
+			return -1, err
+		}
+		return int64(len(jsonBytes)), nil
+	case "osFile":
+		v := part.Value.(os.File)
+		fileInfo, err := v.Stat()
+		if err != nil {
+			return -1, err
+		}
+		if !fileInfo.Mode().IsRegular() {
+			return -1, nil
+		}
+		// The file is copied from its current offset, which is not necessarily its start
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1, err
+		}
+		return max(fileInfo.Size()-offset, 0), nil
+	case "file":
+		return part.Value.(multipart.FileHeader).Size, nil
+	default:
+		return -1, fmt.Errorf("unsupported part type: %T", part.ContentType)
+	}
+}
+
+// contentLength computes the length of the encoded body for the given boundary,
+// or -1 if the size of a part is not known in advance.
+//
+// Parameters:
+// - boundary: The boundary the body is encoded with.
+func (b *MultipartBody) contentLength(boundary string) (int64, error) {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	err := writer.SetBoundary(boundary)
 	if err != nil {
-		return nil, "", err
+		return -1, err
+	}
+	for _, part := range b.Parts {
+		size, err := part.size()
+		if err != nil {
+			return -1, err
+		}
+		if size < 0 {
+			return -1, nil
+		}
+		// Write the part header only and account for the content
+		if _, err = part.createWriter(writer); err != nil {
+			return -1, err
+		}
+		counter.n += size
+	}
+	if err = writer.Close(); err != nil {
+		return -1, err
 	}
+	return counter.n, nil
+}
 
-	// Return the buffer and content type
-	return buf, writer.FormDataContentType(), nil
+// closeFiles closes the os.File parts from the given index on, which were not written.
+//
+// Parameters:
+// - from: The index of the first part to close.
+func (b *MultipartBody) closeFiles(from int) {
+	for _, part := range b.Parts[from:] {
+		if v, ok := part.Value.(os.File); ok && part.ContentType == "osFile" {
+			_ = v.Close() // Intentionally ignoring error as the body is abandoned
+		}
+	}
+}
+
+// countingWriter discards the bytes written to it and counts them.
+type countingWriter struct {
+	n int64
+}
+
+// Write counts the bytes written.
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }